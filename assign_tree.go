@@ -0,0 +1,110 @@
+// +build windows
+
+package winjob
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// AssignTreeError is returned by AssignTree if some of the processes could
+// not be assigned to the job object.
+type AssignTreeError struct {
+	// Errors maps process IDs to the assignment errors.
+	Errors map[int]error
+}
+
+func (e *AssignTreeError) Error() string {
+	pids := make([]int, 0, len(e.Errors))
+	for pid := range e.Errors {
+		pids = append(pids, pid)
+	}
+	sort.Ints(pids)
+	msgs := make([]string, len(pids))
+	for i, pid := range pids {
+		msgs[i] = fmt.Sprintf("process %d: %v", pid, e.Errors[pid])
+	}
+	return fmt.Sprintf("failed to assign %d processes: %s", len(pids), strings.Join(msgs, "; "))
+}
+
+// AssignTree adds the process and all its descendants to the job object.
+// The process tree is built from a CreateToolhelp32Snapshot snapshot, and the
+// processes are assigned starting from the root. If some of the processes
+// could not be assigned (e.g. a process has exited, or access is denied),
+// the remaining ones are still assigned, and *AssignTreeError is returned.
+//
+// Note that the call is not atomic: a process may spawn children after the
+// snapshot is taken, or after the process has been assigned but before its
+// children are. Once a process is assigned, its new children are associated
+// with the job automatically, unless they break away from it. In order to
+// minimize leakage, the job object should be created without WithBreakawayOK
+// and WithSilentBreakawayOK limits.
+func (job *JobObject) AssignTree(rootPID int) error {
+	if err := job.valid(); err != nil {
+		return err
+	}
+	children, err := processChildren()
+	if err != nil {
+		return err
+	}
+	errs := make(map[int]error)
+	visited := map[int]bool{rootPID: true}
+	queue := []int{rootPID}
+	for len(queue) > 0 {
+		pid := queue[0]
+		queue = queue[1:]
+		if err = job.AssignPID(pid); err != nil {
+			errs[pid] = err
+		}
+		for _, child := range children[pid] {
+			// Process IDs may be reused, which may produce cycles.
+			if !visited[child] {
+				visited[child] = true
+				queue = append(queue, child)
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return &AssignTreeError{Errors: errs}
+	}
+	return nil
+}
+
+// processChildren returns process IDs of child processes, grouped by the
+// parent process ID.
+func processChildren() (map[int][]int, error) {
+	s, err := windows.CreateToolhelp32Snapshot(windows.TH32CS_SNAPPROCESS, 0)
+	if err != nil {
+		return nil, fmt.Errorf("CreateToolhelp32Snapshot: %w", err)
+	}
+	defer func() {
+		_ = windows.Close(s)
+	}()
+
+	var e windows.ProcessEntry32
+	e.Size = uint32(unsafe.Sizeof(e))
+	if err := windows.Process32First(s, &e); err != nil {
+		return nil, fmt.Errorf("Process32First: %w", err)
+	}
+
+	children := make(map[int][]int)
+	for {
+		// System processes have no parent.
+		if e.ParentProcessID != 0 && e.ProcessID != e.ParentProcessID {
+			parent := int(e.ParentProcessID)
+			children[parent] = append(children[parent], int(e.ProcessID))
+		}
+		err := windows.Process32Next(s, &e)
+		switch err {
+		default:
+			return nil, fmt.Errorf("Process32Next: %w", err)
+		case windows.ERROR_NO_MORE_FILES:
+			return children, nil
+		case nil:
+		}
+	}
+}
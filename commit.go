@@ -0,0 +1,26 @@
+// +build windows
+
+package winjob
+
+import "github.com/kolesnikovae/go-winjob/jobapi"
+
+// SharedCommit queries the amount of shared committed memory in bytes
+// attributed to the job object. The value allows to avoid double-counting
+// of memory shared between jobs. The information class is supported starting
+// with Windows 10; ErrNotSupported is returned on older systems.
+//
+// Note that JobObjectSharedCommit information class is not documented by
+// Microsoft.
+func (job *JobObject) SharedCommit() (uint64, error) {
+	if err := job.valid(); err != nil {
+		return 0, err
+	}
+	if err := requireBuild(buildWindows10, "shared commit"); err != nil {
+		return 0, err
+	}
+	var info jobapi.JOBOBJECT_SHARED_COMMIT
+	if err := jobapi.QueryInfo(job.Handle, jobapi.JobObjectSharedCommit, &info); err != nil {
+		return 0, err
+	}
+	return info.SharedCommitCharge, nil
+}
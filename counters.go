@@ -0,0 +1,130 @@
+// +build windows
+
+package winjob
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// MarshalJSON implements json.Marshaler. Along with the raw counters, time
+// values are represented in seconds and as time.Duration strings, e.g.:
+// TotalUserTime is accompanied by TotalUserTimeSeconds (1.5) and
+// TotalUserTimeDuration ("1.5s").
+func (c Counters) MarshalJSON() ([]byte, error) {
+	type counters Counters
+	return json.Marshal(struct {
+		counters
+		TotalUserTimeSeconds              float64
+		TotalKernelTimeSeconds            float64
+		ThisPeriodTotalUserTimeSeconds    float64
+		ThisPeriodTotalKernelTimeSeconds  float64
+		TotalUserTimeDuration             counterTime
+		TotalKernelTimeDuration           counterTime
+		ThisPeriodTotalUserTimeDuration   counterTime
+		ThisPeriodTotalKernelTimeDuration counterTime
+	}{
+		counters:                          counters(c),
+		TotalUserTimeSeconds:              ticksToDuration(c.TotalUserTime).Seconds(),
+		TotalKernelTimeSeconds:            ticksToDuration(c.TotalKernelTime).Seconds(),
+		ThisPeriodTotalUserTimeSeconds:    ticksToDuration(c.ThisPeriodTotalUserTime).Seconds(),
+		ThisPeriodTotalKernelTimeSeconds:  ticksToDuration(c.ThisPeriodTotalKernelTime).Seconds(),
+		TotalUserTimeDuration:             counterTime(c.TotalUserTime),
+		TotalKernelTimeDuration:           counterTime(c.TotalKernelTime),
+		ThisPeriodTotalUserTimeDuration:   counterTime(c.ThisPeriodTotalUserTime),
+		ThisPeriodTotalKernelTimeDuration: counterTime(c.ThisPeriodTotalKernelTime),
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler. Raw time counters take
+// precedence; if one is missing, the value is taken from the corresponding
+// time.Duration string, e.g.: TotalUserTimeDuration. Values in seconds are
+// ignored as they are imprecise.
+func (c *Counters) UnmarshalJSON(b []byte) error {
+	type counters Counters
+	var v struct {
+		*counters
+		TotalUserTime                     *uint64
+		TotalKernelTime                   *uint64
+		ThisPeriodTotalUserTime           *uint64
+		ThisPeriodTotalKernelTime         *uint64
+		TotalUserTimeDuration             *counterTime
+		TotalKernelTimeDuration           *counterTime
+		ThisPeriodTotalUserTimeDuration   *counterTime
+		ThisPeriodTotalKernelTimeDuration *counterTime
+	}
+	v.counters = (*counters)(c)
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	c.TotalUserTime = counterTicks(v.TotalUserTime, v.TotalUserTimeDuration)
+	c.TotalKernelTime = counterTicks(v.TotalKernelTime, v.TotalKernelTimeDuration)
+	c.ThisPeriodTotalUserTime = counterTicks(v.ThisPeriodTotalUserTime, v.ThisPeriodTotalUserTimeDuration)
+	c.ThisPeriodTotalKernelTime = counterTicks(v.ThisPeriodTotalKernelTime, v.ThisPeriodTotalKernelTimeDuration)
+	return nil
+}
+
+// counterTicks returns the raw time counter, if present, or the value of
+// the time.Duration string otherwise.
+func counterTicks(raw *uint64, d *counterTime) uint64 {
+	switch {
+	case raw != nil:
+		return *raw
+	case d != nil:
+		return uint64(*d)
+	default:
+		return 0
+	}
+}
+
+// counterTime is a time counter in 100-nanosecond ticks, which is encoded
+// to JSON as a time.Duration string.
+type counterTime uint64
+
+func (t counterTime) MarshalJSON() ([]byte, error) {
+	return json.Marshal(ticksToDuration(uint64(t)).String())
+}
+
+func (t *counterTime) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	if d < 0 {
+		return fmt.Errorf("negative time counter: %q", s)
+	}
+	*t = counterTime(d.Nanoseconds() / timeFraction)
+	return nil
+}
+
+// ticksToDuration converts 100-nanosecond ticks to time.Duration.
+func ticksToDuration(ticks uint64) time.Duration {
+	return time.Duration(ticks * timeFraction)
+}
+
+// CPUPercent calculates CPU usage of the job object, in percent, between the
+// previous counters sample and the current one, taken wall time apart. The
+// value is normalized per processor: the result is in the range from 0 to
+// 100*numCPU. If the previous sample is empty (the first sample), or the
+// arguments are invalid, 0 is returned.
+func (c Counters) CPUPercent(prev Counters, wall time.Duration, numCPU int) float64 {
+	if prev == (Counters{}) || wall <= 0 || numCPU <= 0 {
+		return 0
+	}
+	cur := c.TotalUserTime + c.TotalKernelTime
+	last := prev.TotalUserTime + prev.TotalKernelTime
+	if cur <= last {
+		return 0
+	}
+	busy := ticksToDuration(cur - last)
+	p := float64(busy) / float64(wall) * 100
+	if max := float64(100 * numCPU); p > max {
+		return max
+	}
+	return p
+}
@@ -0,0 +1,77 @@
+// +build windows
+
+package winjob_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/kolesnikovae/go-winjob"
+)
+
+func TestCounters_CPUPercent(t *testing.T) {
+	const tick = uint64(time.Millisecond / 100)
+	prev := winjob.Counters{TotalUserTime: 100 * tick, TotalKernelTime: 100 * tick}
+	for _, x := range []struct {
+		cur      winjob.Counters
+		prev     winjob.Counters
+		wall     time.Duration
+		numCPU   int
+		expected float64
+	}{
+		{winjob.Counters{TotalUserTime: 600 * tick, TotalKernelTime: 100 * tick}, prev, time.Second, 1, 50},
+		{winjob.Counters{TotalUserTime: 600 * tick, TotalKernelTime: 600 * tick}, prev, time.Second, 2, 100},
+		{winjob.Counters{TotalUserTime: 5000 * tick}, prev, time.Second, 2, 200},
+		{winjob.Counters{TotalUserTime: 600 * tick}, winjob.Counters{}, time.Second, 1, 0},
+		{prev, prev, time.Second, 1, 0},
+		{prev, prev, 0, 1, 0},
+	} {
+		if actual := x.cur.CPUPercent(x.prev, x.wall, x.numCPU); actual != x.expected {
+			t.Fatalf("Expected %v, got %v", x.expected, actual)
+		}
+	}
+}
+
+func TestCounters_MarshalJSON(t *testing.T) {
+	c := winjob.Counters{
+		TotalUserTime:      15000000,
+		TotalKernelTime:    5000000,
+		ReadOperationCount: 52,
+	}
+	b, err := json.Marshal(c)
+	requireNoError(t, err)
+	var m map[string]interface{}
+	requireNoError(t, json.Unmarshal(b, &m))
+	for k, v := range map[string]interface{}{
+		"TotalUserTime":                   float64(15000000),
+		"TotalUserTimeSeconds":            1.5,
+		"TotalUserTimeDuration":           "1.5s",
+		"TotalKernelTime":                 float64(5000000),
+		"TotalKernelTimeSeconds":          0.5,
+		"TotalKernelTimeDuration":         "500ms",
+		"ThisPeriodTotalUserTimeDuration": "0s",
+		"ReadOperationCount":              float64(52),
+	} {
+		if m[k] != v {
+			t.Fatalf("%s: expected %v, got %v", k, v, m[k])
+		}
+	}
+	var actual winjob.Counters
+	requireNoError(t, json.Unmarshal(b, &actual))
+	if actual != c {
+		t.Fatalf("Expected %+v, got %+v", c, actual)
+	}
+}
+
+func TestCounters_UnmarshalJSON(t *testing.T) {
+	var c winjob.Counters
+	b := []byte(`{"TotalUserTime":15000000,"TotalUserTimeDuration":"1s","TotalKernelTimeDuration":"2s"}`)
+	requireNoError(t, json.Unmarshal(b, &c))
+	if c.TotalUserTime != 15000000 || c.TotalKernelTime != 20000000 {
+		t.Fatalf("Unexpected counters: %+v", c)
+	}
+	if err := json.Unmarshal([]byte(`{"TotalUserTimeDuration":"-1s"}`), &c); err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+}
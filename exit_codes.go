@@ -0,0 +1,122 @@
+// +build windows
+
+package winjob
+
+import (
+	"context"
+	"syscall"
+
+	"github.com/kolesnikovae/go-winjob/jobapi"
+)
+
+// stillActive is the exit code reported for a process that has not exited.
+const stillActive = 259
+
+// CollectExitCodes records exit codes of the job object processes until the
+// job has no active processes, or the context is done, whichever occurs
+// first. In the latter case the exit codes collected so far are returned
+// along with the context error. The returned map is keyed by process ID.
+//
+// Every process is opened on NotificationNewProcess message in order to read
+// its exit code once it exits. Processes already associated with the job are
+// opened when the call starts, and the process list is re-checked
+// periodically, since message delivery is not guaranteed. Collection is
+// best-effort: a process that exits before it can be opened (e.g. a
+// short-lived one) is not present in the map. Notifications are received the
+// same way as Wait does: an active subscription is reused, otherwise a
+// completion port is associated with the job object for the duration of the
+// call.
+func (job *JobObject) CollectExitCodes(ctx context.Context) (map[int]uint32, error) {
+	e, release := job.events()
+	defer release()
+
+	c := exitCodeCollector{
+		handles: make(map[int]syscall.Handle),
+		codes:   make(map[int]uint32),
+	}
+	defer c.close()
+	for {
+		if err := job.ForEachProcess(func(pid int) error {
+			c.track(pid)
+			return nil
+		}); err != nil {
+			return c.codes, err
+		}
+		info, err := job.BasicAccounting()
+		if err != nil {
+			return c.codes, err
+		}
+		if info.ActiveProcesses == 0 {
+			c.recordAll()
+			return c.codes, nil
+		}
+		if err = c.wait(ctx, &e); err != nil {
+			// Processes that have exited meanwhile are recorded.
+			c.recordAll()
+			return c.codes, err
+		}
+	}
+}
+
+type exitCodeCollector struct {
+	handles map[int]syscall.Handle
+	codes   map[int]uint32
+}
+
+// wait handles completion port messages until signalPollInterval elapses,
+// NotificationActiveProcessZero is received, or the context is done.
+func (c *exitCodeCollector) wait(ctx context.Context, e *jobEvents) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		m, ok, err := e.next(ctx, signalPollInterval)
+		if err != nil || !ok {
+			return err
+		}
+		switch m.Type {
+		case NotificationNewProcess:
+			c.track(m.PID)
+		case NotificationExitProcess, NotificationAbnormalExitProcess:
+			c.record(m.PID)
+		case NotificationActiveProcessZero:
+			return nil
+		}
+	}
+}
+
+func (c *exitCodeCollector) track(pid int) {
+	if _, ok := c.handles[pid]; ok {
+		return
+	}
+	h, err := syscall.OpenProcess(jobapi.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if err == nil {
+		c.handles[pid] = h
+	}
+}
+
+func (c *exitCodeCollector) record(pid int) {
+	h, ok := c.handles[pid]
+	if !ok {
+		return
+	}
+	var code uint32
+	if err := syscall.GetExitCodeProcess(h, &code); err != nil || code == stillActive {
+		return
+	}
+	c.codes[pid] = code
+	_ = syscall.CloseHandle(h)
+	delete(c.handles, pid)
+}
+
+func (c *exitCodeCollector) recordAll() {
+	for pid := range c.handles {
+		c.record(pid)
+	}
+}
+
+func (c *exitCodeCollector) close() {
+	for _, h := range c.handles {
+		_ = syscall.CloseHandle(h)
+	}
+}
@@ -0,0 +1,72 @@
+// +build windows
+
+package winjob
+
+import (
+	"errors"
+	"fmt"
+
+	"golang.org/x/sys/windows"
+)
+
+// ErrNotSupported is returned when a feature is not supported by the running
+// operating system.
+var ErrNotSupported = errors.New("not supported by the operating system")
+
+// Windows builds in which job object features were introduced.
+const (
+	// Windows 8 and Windows Server 2012.
+	buildWindows8 = 9200
+	// Windows 10, version 1507.
+	buildWindows10 = 10240
+	// Windows 10, version 1607 and Windows Server 2016.
+	buildWindows10v1607 = 14393
+)
+
+// WindowsBuild returns the build number of the running operating system.
+func WindowsBuild() uint32 {
+	return windows.RtlGetVersion().BuildNumber
+}
+
+// Features describes job object features supported by the running
+// operating system.
+type Features struct {
+	// NotificationLimit2 is JobObjectNotificationLimitInformation2
+	// information class support.
+	NotificationLimit2 bool
+	// Silos is server silo support, refer to IsSilo.
+	Silos bool
+	// Freeze is job freezing support, refer to IsFrozen.
+	Freeze bool
+	// IORateControl is I/O rate control support.
+	IORateControl bool
+	// NetRateControl is network rate control support, refer to
+	// WithOutgoingBandwidthLimit and WithDSCPTag.
+	NetRateControl bool
+	// MemoryUsage is JobObjectMemoryUsageInformation information class
+	// support.
+	MemoryUsage bool
+}
+
+// SupportedFeatures returns job object features supported by the running
+// operating system, based on its build number.
+func SupportedFeatures() Features {
+	build := WindowsBuild()
+	return Features{
+		NotificationLimit2: build >= buildWindows10,
+		Silos:              build >= buildWindows10v1607,
+		Freeze:             build >= buildWindows8,
+		IORateControl:      build >= buildWindows10,
+		NetRateControl:     build >= buildWindows10,
+		MemoryUsage:        build >= buildWindows8,
+	}
+}
+
+// requireBuild returns ErrNotSupported wrapped with the feature name if the
+// running operating system build is older than the one specified.
+func requireBuild(build uint32, feature string) error {
+	if WindowsBuild() < build {
+		return fmt.Errorf("%s: %w", feature, ErrNotSupported)
+	}
+	return nil
+}
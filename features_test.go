@@ -0,0 +1,21 @@
+// +build windows
+
+package winjob_test
+
+import (
+	"testing"
+
+	"github.com/kolesnikovae/go-winjob"
+)
+
+func TestSupportedFeatures(t *testing.T) {
+	build := winjob.WindowsBuild()
+	if build == 0 {
+		t.Fatal("Unknown Windows build")
+	}
+	f := winjob.SupportedFeatures()
+	t.Logf("Windows build %d: %+v", build, f)
+	if f.Silos && !f.Freeze {
+		t.Fatal("Silos are not expected to be supported without freeze")
+	}
+}
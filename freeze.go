@@ -0,0 +1,90 @@
+// +build windows
+
+package winjob
+
+import "github.com/kolesnikovae/go-winjob/jobapi"
+
+// FreezeInfo describes the freeze state of a job object.
+type FreezeInfo struct {
+	// Flags specify which of the fields below are meaningful:
+	//  - FreezeOperation: Frozen;
+	//  - FilterOperation: HighEdgeFilter and LowEdgeFilter;
+	//  - SwapOperation: Swapped.
+	Flags jobapi.FreezeFlag
+	// Frozen is true if the job processes are frozen.
+	Frozen bool
+	// Swapped is true if the job processes memory is swapped out.
+	Swapped bool
+	// HighEdgeFilter and LowEdgeFilter are the wake filter bits: each bit
+	// corresponds to a wake reason which thaws the job, when set by the
+	// system, e.g. on application resume.
+	HighEdgeFilter uint32
+	LowEdgeFilter  uint32
+}
+
+// IsFrozen reports whether the job object is frozen. Job freezing is
+// supported starting with Windows 8 and Windows Server 2012; ErrNotSupported
+// is returned on older systems.
+//
+// Note that JobObjectFreezeInformation information class is not documented
+// by Microsoft.
+func (job *JobObject) IsFrozen() (bool, error) {
+	info, err := job.FreezeInfo()
+	if err != nil {
+		return false, err
+	}
+	return info.Frozen, nil
+}
+
+// FreezeInfo returns the freeze state of the job object, including the wake
+// filter. A job may be frozen by the system, e.g. when a UWP application is
+// suspended, and thawed on a wake event. Job freezing is supported starting
+// with Windows 8 and Windows Server 2012; ErrNotSupported is returned on
+// older systems.
+func (job *JobObject) FreezeInfo() (FreezeInfo, error) {
+	if err := job.valid(); err != nil {
+		return FreezeInfo{}, err
+	}
+	if err := requireBuild(buildWindows8, "job freeze"); err != nil {
+		return FreezeInfo{}, err
+	}
+	var info jobapi.JOBOBJECT_FREEZE_INFORMATION
+	if err := jobapi.QueryInfo(job.Handle, jobapi.JobObjectFreezeInformation, &info); err != nil {
+		return FreezeInfo{}, err
+	}
+	return FreezeInfo{
+		Flags:          info.Flags,
+		Frozen:         info.Freeze,
+		Swapped:        info.Swap,
+		HighEdgeFilter: info.WakeFilter.HighEdgeFilter,
+		LowEdgeFilter:  info.WakeFilter.LowEdgeFilter,
+	}, nil
+}
+
+// Freeze suspends all the processes associated with the job object. Unlike
+// ResumeProcess, the call does not enumerate threads: the system freezes the
+// whole process tree at once, including processes that are assigned to the
+// job later. Job freezing is supported starting with Windows 8 and Windows
+// Server 2012; ErrNotSupported is returned on older systems.
+func (job *JobObject) Freeze() error {
+	return job.setFrozen(true)
+}
+
+// Thaw resumes the job object processes suspended with Freeze.
+func (job *JobObject) Thaw() error {
+	return job.setFrozen(false)
+}
+
+func (job *JobObject) setFrozen(freeze bool) error {
+	if err := job.valid(); err != nil {
+		return err
+	}
+	if err := requireBuild(buildWindows8, "job freeze"); err != nil {
+		return err
+	}
+	info := jobapi.JOBOBJECT_FREEZE_INFORMATION{
+		Flags:  jobapi.FreezeOperation,
+		Freeze: freeze,
+	}
+	return jobapi.SetInfo(job.Handle, jobapi.JobObjectFreezeInformation, &info)
+}
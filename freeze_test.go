@@ -0,0 +1,76 @@
+// +build windows
+
+package winjob_test
+
+import (
+	"errors"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/kolesnikovae/go-winjob"
+)
+
+func TestIsFrozen(t *testing.T) {
+	runTestWithEmptyJobObject(t, func(job *winjob.JobObject) {
+		frozen, err := job.IsFrozen()
+		if errors.Is(err, winjob.ErrNotSupported) {
+			t.Skip(err)
+		}
+		requireNoError(t, err)
+		if frozen {
+			t.Fatal("Job object is not expected to be frozen")
+		}
+	})
+}
+
+func TestFreezeInfo(t *testing.T) {
+	runTestWithEmptyJobObject(t, func(job *winjob.JobObject) {
+		info, err := job.FreezeInfo()
+		if errors.Is(err, winjob.ErrNotSupported) {
+			t.Skip(err)
+		}
+		requireNoError(t, err)
+		if info.Frozen || info.Swapped {
+			t.Fatalf("Job object is not expected to be frozen: %+v", info)
+		}
+	})
+}
+
+func TestFreeze(t *testing.T) {
+	runTestWithEmptyJobObject(t, func(job *winjob.JobObject) {
+		// The process spins in an infinite loop.
+		cmd := exec.Command("cmd.exe", "/c", "for /l %i in () do rem")
+		requireNoError(t, winjob.StartInJobObject(cmd, job))
+		defer func() {
+			requireNoError(t, job.Terminate())
+			_ = cmd.Wait()
+		}()
+		err := job.Freeze()
+		if errors.Is(err, winjob.ErrNotSupported) {
+			t.Skip(err)
+		}
+		requireNoError(t, err)
+		frozen, err := job.IsFrozen()
+		requireNoError(t, err)
+		if !frozen {
+			t.Fatal("Job object is expected to be frozen")
+		}
+		time.Sleep(time.Millisecond * 100)
+		before, err := job.Counters()
+		requireNoError(t, err)
+		time.Sleep(time.Millisecond * 500)
+		after, err := job.Counters()
+		requireNoError(t, err)
+		if after.TotalUserTime != before.TotalUserTime {
+			t.Fatalf("CPU time accumulated while frozen: %d -> %d",
+				before.TotalUserTime, after.TotalUserTime)
+		}
+		requireNoError(t, job.Thaw())
+		frozen, err = job.IsFrozen()
+		requireNoError(t, err)
+		if frozen {
+			t.Fatal("Job object is not expected to be frozen")
+		}
+	})
+}
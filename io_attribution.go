@@ -0,0 +1,25 @@
+// +build windows
+
+package winjob
+
+import "github.com/kolesnikovae/go-winjob/jobapi"
+
+// IOAttribution queries the job object for I/O attribution information:
+// read and write operation counts and bytes attributed to the job. Unlike
+// I/O counters of Counters, the statistics account I/O at the storage stack
+// level, which is more precise for shared storage. The information class is
+// supported starting with Windows 10, version 1607; ErrNotSupported is
+// returned on older systems.
+func (job *JobObject) IOAttribution() (*jobapi.JOBOBJECT_IO_ATTRIBUTION_INFORMATION, error) {
+	if err := job.valid(); err != nil {
+		return nil, err
+	}
+	if err := requireBuild(buildWindows10v1607, "I/O attribution"); err != nil {
+		return nil, err
+	}
+	var info jobapi.JOBOBJECT_IO_ATTRIBUTION_INFORMATION
+	if err := jobapi.QueryInfo(job.Handle, jobapi.JobObjectIoAttribution, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
@@ -3,8 +3,18 @@
 package winjob
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
 	"os"
+	"runtime"
+	"strings"
 	"syscall"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
 
 	"github.com/kolesnikovae/go-winjob/jobapi"
 )
@@ -22,6 +32,25 @@ type JobObject struct {
 	Name   string
 	Handle syscall.Handle
 	JobInfo
+
+	// labeled is true if Name is not a kernel object name.
+	labeled bool
+
+	// requestedPriorityClass is the priority class last requested with
+	// WithPriorityClassLimit, refer to EffectivePriorityClass.
+	requestedPriorityClass jobapi.PriorityClass
+
+	// portAssociated is true if a completion port has been associated
+	// with the job object, refer to ErrPortAlreadyAssociated.
+	portAssociated bool
+	// subscription is the last subscription created with Notify, refer
+	// to Wait.
+	subscription *Subscription
+
+	// parent is the job the job object has been created in with
+	// CreateChild, refer to IsNested.
+	parent *JobObject
+	nested bool
 }
 
 // Limit manages a job object limits.
@@ -67,6 +96,10 @@ type Counters struct {
 	ReadTransferCount   uint64
 	WriteTransferCount  uint64
 	OtherTransferCount  uint64
+
+	// Peak memory usage is only populated by QueryCountersWithMemory.
+	PeakProcessMemoryUsed uint64
+	PeakJobMemoryUsed     uint64
 }
 
 type JobInfo struct {
@@ -75,14 +108,33 @@ type JobInfo struct {
 	AccountingInfo jobapi.JOBOBJECT_BASIC_AND_IO_ACCOUNTING_INFORMATION
 	CPURateControl jobapi.JOBOBJECT_CPU_RATE_CONTROL_INFORMATION
 	NetRateControl jobapi.JOBOBJECT_NET_RATE_CONTROL_INFORMATION
+	IORateControl  jobapi.JOBOBJECT_IO_RATE_CONTROL_INFORMATION
+	EndOfJobTime   jobapi.JOBOBJECT_END_OF_JOB_TIME_INFORMATION
+
+	NotificationLimits jobapi.JOBOBJECT_NOTIFICATION_LIMIT_INFORMATION
 }
 
 // Create creates a new job object. An anonymous job object will be created,
 // if a name is not provided. One or more job object limits may be specified:
 // refer to limits documentation for details. If limits fail to apply, created
-// job object will be disposed.
+// job object will be disposed. The name may include "Global\" or "Local\"
+// namespace prefix, but must not contain backslashes otherwise: such names
+// are rejected with ErrInvalidName.
 func Create(name string, limits ...Limit) (*JobObject, error) {
-	hJobObject, err := jobapi.CreateJobObject(name, jobapi.MakeSA())
+	return CreateWithSecurity(name, jobapi.MakeSA(), limits...)
+}
+
+// CreateWithSecurity creates a new job object the same way as Create does,
+// but allows to specify the security attributes of the job object: e.g. to
+// make the handle inheritable, or to provide a custom security descriptor
+// that grants access to the job object to a less privileged process. If sa
+// is nil, the job object gets a default security descriptor, and the handle
+// is not inheritable.
+func CreateWithSecurity(name string, sa *syscall.SecurityAttributes, limits ...Limit) (*JobObject, error) {
+	if err := validateName(name); err != nil {
+		return nil, err
+	}
+	hJobObject, err := jobapi.CreateJobObject(name, sa)
 	if err != nil {
 		return nil, err
 	}
@@ -99,15 +151,69 @@ func Create(name string, limits ...Limit) (*JobObject, error) {
 	return &job, nil
 }
 
+// CreateLabeled creates a new anonymous job object the same way as Create
+// does, but sets the job object Name to the label given. The label is only
+// meant for display and correlation purposes (e.g., logging): it is not a
+// kernel object name, therefore the job object can not be opened with it.
+func CreateLabeled(label string, limits ...Limit) (*JobObject, error) {
+	job, err := Create("", limits...)
+	if err != nil {
+		return nil, err
+	}
+	job.Name = label
+	job.labeled = true
+	return job, nil
+}
+
+// ErrInvalidName is returned if a job object name is not valid.
+var ErrInvalidName = errors.New("invalid job object name")
+
+// validateName checks the job object name before it is passed to the
+// system. Backslashes are object directory separators, therefore a name
+// may only contain one as a part of the "Global\" or "Local\" prefix.
+func validateName(name string) error {
+	n := name
+	for _, prefix := range []string{`Global\`, `Local\`} {
+		if strings.HasPrefix(n, prefix) {
+			n = n[len(prefix):]
+			break
+		}
+	}
+	if strings.ContainsRune(n, '\\') {
+		return fmt.Errorf("%w %q: backslash is only allowed "+
+			"in the Global\\ or Local\\ namespace prefix", ErrInvalidName, name)
+	}
+	return nil
+}
+
 // Open opens existing job object by its name. A job is being opened with
 // JOB_OBJECT_ALL_ACCESS access rights.
 func Open(name string) (*JobObject, error) {
 	return OpenWithAccess(name, jobapi.JOB_OBJECT_ALL_ACCESS)
 }
 
+// OpenForQuery opens existing job object by its name with JOB_OBJECT_QUERY
+// access right only. This allows to inspect the job object limits and
+// accounting information if the caller is not granted full access, e.g. for
+// monitoring purposes. Any attempt to modify the job object fails.
+func OpenForQuery(name string) (*JobObject, error) {
+	return OpenWithAccess(name, jobapi.JOB_OBJECT_QUERY)
+}
+
+// ErrJobNotExist is returned if a job object with the given name does not
+// exist.
+var ErrJobNotExist = errors.New("job object does not exist")
+
 // Open opens existing job object by its name with access rights specified.
+// If the job object does not exist, ErrJobNotExist is returned.
 func OpenWithAccess(name string, access uintptr) (*JobObject, error) {
+	if err := validateName(name); err != nil {
+		return nil, err
+	}
 	hJobObject, err := jobapi.OpenJobObject(access, 0, name)
+	if errors.Is(err, windows.ERROR_FILE_NOT_FOUND) {
+		return nil, fmt.Errorf("%w: %q", ErrJobNotExist, name)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -118,9 +224,113 @@ func OpenWithAccess(name string, access uintptr) (*JobObject, error) {
 	return &job, nil
 }
 
-// Close closes job object handle.
+// ErrAnonymousJob is returned by Reopen if the job object has no kernel
+// object name.
+var ErrAnonymousJob = errors.New("anonymous job object can not be reopened")
+
+// Reopen opens a new handle to the named job object with JOB_OBJECT_ALL_ACCESS
+// access rights, and replaces the current one, which is closed, if valid.
+// The cached JobInfo is retained. Note that a job object is destroyed once
+// its last handle is closed, therefore Reopen only succeeds as long as the
+// job object is kept alive by other handles or associated processes. In this
+// case ErrJobNotExist is returned.
+//
+// Anonymous and labeled job objects can not be reopened: ErrAnonymousJob is
+// returned.
+func (job *JobObject) Reopen() error {
+	if !job.named() {
+		return ErrAnonymousJob
+	}
+	reopened, err := Open(job.Name)
+	if err != nil {
+		return err
+	}
+	if job.valid() == nil {
+		_ = job.Close()
+	}
+	job.Handle = reopened.Handle
+	return nil
+}
+
+// QueryName retrieves the kernel object name of the job object, which may be
+// useful if the handle was duplicated or inherited. The name includes the
+// object directory path, e.g.: \Sessions\1\BaseNamedObjects\name. If the
+// job object is anonymous, an empty string is returned.
+func (job *JobObject) QueryName() (string, error) {
+	if err := job.valid(); err != nil {
+		return "", err
+	}
+	return jobapi.QueryObjectName(job.Handle)
+}
+
+// SameAs reports whether the job object and the other one refer to the same
+// underlying kernel object. CompareObjectHandles is used where available
+// (Windows 10, version 1607 and later), otherwise job object names are
+// compared: anonymous job objects are only considered the same, if their
+// handles are equal. ErrInvalidHandle is returned if either of the job
+// objects has no valid handle.
+func (job *JobObject) SameAs(other *JobObject) (bool, error) {
+	if other == nil {
+		return false, ErrInvalidHandle
+	}
+	if err := job.valid(); err != nil {
+		return false, err
+	}
+	if err := other.valid(); err != nil {
+		return false, err
+	}
+	if requireBuild(buildWindows10v1607, "CompareObjectHandles") == nil {
+		return jobapi.CompareObjectHandles(job.Handle, other.Handle)
+	}
+	if !job.named() || !other.named() {
+		return job.Handle == other.Handle, nil
+	}
+	return job.Name == other.Name, nil
+}
+
+// ErrInvalidHandle is returned if the job object handle is not initialized,
+// e.g. the JobObject has not been created with Create or Open call.
+var ErrInvalidHandle = errors.New("invalid job object handle")
+
+// valid returns ErrInvalidHandle if the job object handle is not set.
+func (job *JobObject) valid() error {
+	if job.Handle == 0 || job.Handle == syscall.InvalidHandle {
+		return ErrInvalidHandle
+	}
+	return nil
+}
+
+// named reports whether the job object has a kernel object name.
+func (job *JobObject) named() bool {
+	return job.Name != "" && !job.labeled
+}
+
+var _ io.Closer = (*JobObject)(nil)
+
+// Close closes job object handle. Once the handle is closed, it is replaced
+// with syscall.InvalidHandle, and subsequent calls return nil.
 func (job *JobObject) Close() error {
-	return syscall.Close(job.Handle)
+	if job.Handle == syscall.InvalidHandle {
+		return nil
+	}
+	if err := syscall.Close(job.Handle); err != nil {
+		return err
+	}
+	job.Handle = syscall.InvalidHandle
+	return nil
+}
+
+// KeepAlive marks the job object as reachable up to the call, the same way
+// as runtime.KeepAlive does. The package does not set finalizers, therefore
+// the job object handle is only closed with Close call, or when the process
+// exits. However, if the JobObject is owned by a value with a finalizer that
+// closes the job (e.g. a pool of workers), KeepAlive prevents the job from
+// being closed prematurely during long waits:
+//
+//  defer job.KeepAlive()
+//  return cmd.Wait()
+func (job *JobObject) KeepAlive() {
+	runtime.KeepAlive(job)
 }
 
 // Terminate destroys the job object and all the associated processes.
@@ -134,18 +344,197 @@ func (job *JobObject) Terminate() error {
 // TerminateWithExitCode terminates the job object. All the processes and
 // threads in the job object will use the exit code provided.
 func (job *JobObject) TerminateWithExitCode(exitCode uint32) error {
+	if err := job.valid(); err != nil {
+		return err
+	}
 	return jobapi.TerminateJobObject(job.Handle, exitCode)
 }
 
+// signalPollInterval specifies how often the job object state is checked
+// while waiting for the job to become signaled.
+const signalPollInterval = 100 * time.Millisecond
+
+// WaitSignaled blocks until the job object becomes signaled, or the context
+// is done, whichever occurs first. In the latter case the context error is
+// returned.
+//
+// Microsoft documentation says the following: the state of a job object is
+// set to signaled when all of its processes are terminated because the
+// specified end-of-job time limit has been exceeded (see WithJobTimeLimit).
+// Note that the job object is not signaled when its processes exit
+// normally: use completion port notifications to track the job state.
+func (job *JobObject) WaitSignaled(ctx context.Context) error {
+	if err := job.valid(); err != nil {
+		return err
+	}
+	ms := uint32(signalPollInterval / time.Millisecond)
+	for {
+		event, err := windows.WaitForSingleObject(windows.Handle(job.Handle), ms)
+		switch {
+		case err != nil:
+			return os.NewSyscallError("WaitForSingleObject", err)
+		case event == windows.WAIT_OBJECT_0:
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+	}
+}
+
+// ErrProcessNotInJob is returned when a process is expected to be associated
+// with the job object, but it is not.
+var ErrProcessNotInJob = errors.New("process is not associated with the job object")
+
+// TerminateProcess terminates the process of the job object with the given
+// exit code, leaving other processes of the job running. The process is
+// opened with PROCESS_TERMINATE and PROCESS_QUERY_LIMITED_INFORMATION access
+// rights; if it is not associated with the job, ErrProcessNotInJob is
+// returned and the process is left intact.
+func (job *JobObject) TerminateProcess(pid int, exitCode uint32) error {
+	if err := job.valid(); err != nil {
+		return err
+	}
+	desiredAccess := jobapi.PROCESS_TERMINATE | jobapi.PROCESS_QUERY_LIMITED_INFORMATION
+	return withProcessHandle(pid, desiredAccess, func(h syscall.Handle) error {
+		found, err := jobapi.IsProcessInJob(h, job.Handle)
+		switch {
+		case err != nil:
+			return err
+		case !found:
+			return ErrProcessNotInJob
+		}
+		if err = windows.TerminateProcess(windows.Handle(h), exitCode); err != nil {
+			return os.NewSyscallError("TerminateProcess", err)
+		}
+		return nil
+	})
+}
+
 // Assign opens specified process by PID and adds it to the job object.
 // When a process is associated with a job, the association cannot be
 // broken. A process can be associated with more than one job object in a
-// hierarchy of nested jobs (OS-dependent). The process is opened with
-// PROCESS_ALL_ACCESS access rights.
+// hierarchy of nested jobs (OS-dependent).
+//
+// The process is opened with PROCESS_SET_QUOTA and PROCESS_TERMINATE access
+// rights, the minimum required for the assignment. Note that earlier versions
+// requested PROCESS_ALL_ACCESS, which fails for processes the caller can not
+// fully access, e.g. protected ones. Use AssignWithAccess, if other access
+// rights are needed.
 func (job *JobObject) Assign(p *os.Process) error {
-	desiredAccess := jobapi.PROCESS_ALL_ACCESS
+	return job.AssignWithAccess(p, assignAccess)
+}
+
+// assignAccess is the minimum access rights required to associate a process
+// with a job object.
+const assignAccess = jobapi.PROCESS_SET_QUOTA | jobapi.PROCESS_TERMINATE
+
+// AssignWithAccess adds the process to the job object the same way as
+// Assign does, but the process is opened with the access rights given.
+// The access rights must include PROCESS_SET_QUOTA and PROCESS_TERMINATE.
+func (job *JobObject) AssignWithAccess(p *os.Process, access int) error {
+	if err := job.valid(); err != nil {
+		return err
+	}
+	return withProcessHandle(p.Pid, access, job.AssignHandle)
+}
+
+// AssignPID adds the process with the given identifier to the job object
+// the same way as Assign does. The process is opened with PROCESS_SET_QUOTA
+// and PROCESS_TERMINATE access rights.
+func (job *JobObject) AssignPID(pid int) error {
+	if err := job.valid(); err != nil {
+		return err
+	}
+	return withProcessHandle(pid, assignAccess, job.AssignHandle)
+}
+
+// AssignHandle adds the process to the job object using the process handle
+// given, which allows the caller to control the access rights the process is
+// opened with. The handle must have PROCESS_SET_QUOTA and PROCESS_TERMINATE
+// access rights. The handle is not closed by the call.
+//
+// If the process is already associated with a job object that the job can
+// not be nested in, ErrProcessAlreadyInJob is returned.
+func (job *JobObject) AssignHandle(h syscall.Handle) error {
+	if err := job.valid(); err != nil {
+		return err
+	}
+	return job.assignProcess(h)
+}
+
+// ErrProcessAlreadyInJob is returned when a process can not be associated with
+// a job object because it already belongs to another job object, and the jobs
+// can not form a hierarchy. Prior to Windows 8 a process can only belong to a
+// single job object. Starting with Windows 8 jobs can be nested, but only if
+// the job being assigned has no processes outside of the job the process
+// belongs to. The original system error, ERROR_ACCESS_DENIED, is kept in the
+// error chain.
+var ErrProcessAlreadyInJob = errors.New("process is already associated with an incompatible job object")
+
+type processAlreadyInJobError struct {
+	err error
+}
+
+func (e *processAlreadyInJobError) Error() string {
+	return ErrProcessAlreadyInJob.Error() + ": " + e.err.Error()
+}
+
+func (e *processAlreadyInJobError) Unwrap() error {
+	return e.err
+}
+
+func (e *processAlreadyInJobError) Is(target error) bool {
+	return target == ErrProcessAlreadyInJob
+}
+
+func assignProcessToJob(hJobObject, hProcess syscall.Handle) error {
+	err := jobapi.AssignProcessToJobObject(hJobObject, hProcess)
+	if errors.Is(err, windows.ERROR_ACCESS_DENIED) {
+		return &processAlreadyInJobError{err: err}
+	}
+	return err
+}
+
+// AssignCurrentProcess adds the calling process to the job object. Child
+// processes created afterwards are associated with the job, unless they
+// break away from it, refer to StartBreakaway.
+//
+// Prior to Windows 8 a process can only be associated with a single job
+// object: if the calling process already belongs to one (e.g. it has been
+// started by a service manager or a shell that uses jobs), the call fails
+// with ErrProcessAlreadyInJob.
+func (job *JobObject) AssignCurrentProcess() error {
+	if err := job.valid(); err != nil {
+		return err
+	}
+	return job.assignProcess(syscall.Handle(windows.CurrentProcess()))
+}
+
+// AssignVerified adds the process to the job object the same way as Assign
+// does, and then ensures the process is actually associated with the job.
+// If the assignment did not take effect, an error wrapping ErrProcessNotInJob
+// is returned. The process is additionally opened with
+// PROCESS_QUERY_LIMITED_INFORMATION access right for the check.
+func (job *JobObject) AssignVerified(p *os.Process) error {
+	if err := job.valid(); err != nil {
+		return err
+	}
+	desiredAccess := assignAccess | jobapi.PROCESS_QUERY_LIMITED_INFORMATION
 	return withProcessHandle(p.Pid, desiredAccess, func(h syscall.Handle) error {
-		return jobapi.AssignProcessToJobObject(job.Handle, h)
+		if err := job.assignProcess(h); err != nil {
+			return err
+		}
+		found, err := jobapi.IsProcessInJob(h, job.Handle)
+		switch {
+		case err != nil:
+			return err
+		case !found:
+			return fmt.Errorf("assigned process %d: %w", p.Pid, ErrProcessNotInJob)
+		}
+		return nil
 	})
 }
 
@@ -153,6 +542,9 @@ func (job *JobObject) Assign(p *os.Process) error {
 // The process is opened with PROCESS_QUERY_LIMITED_INFORMATION access
 // rights.
 func (job *JobObject) Contains(p *os.Process) (found bool, err error) {
+	if err = job.valid(); err != nil {
+		return false, err
+	}
 	desiredAccess := jobapi.PROCESS_QUERY_LIMITED_INFORMATION
 	err = withProcessHandle(p.Pid, desiredAccess, func(h syscall.Handle) error {
 		found, err = jobapi.IsProcessInJob(h, job.Handle)
@@ -161,6 +553,66 @@ func (job *JobObject) Contains(p *os.Process) (found bool, err error) {
 	return found, err
 }
 
+// ForEachProcess queries the list of processes associated with the job
+// object and calls fn for every process ID. Iteration stops at the first
+// error returned by fn, and the error is returned. If the job is nested,
+// the list includes processes of all the child jobs.
+func (job *JobObject) ForEachProcess(fn func(pid int) error) error {
+	if err := job.valid(); err != nil {
+		return err
+	}
+	pids, err := jobapi.QueryProcessIDList(job.Handle)
+	if err != nil {
+		return err
+	}
+	for _, pid := range pids {
+		if err = fn(int(pid)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ProcessIDs returns identifiers of all the processes associated with the
+// job object. If the job is nested, the list includes processes of all the
+// child jobs. Processes may be assigned to the job, or exit, concurrently,
+// therefore the list only reflects the job state at the moment of the call.
+func (job *JobObject) ProcessIDs() ([]int, error) {
+	if err := job.valid(); err != nil {
+		return nil, err
+	}
+	list, err := jobapi.QueryProcessIDList(job.Handle)
+	if err != nil {
+		return nil, err
+	}
+	pids := make([]int, len(list))
+	for i, pid := range list {
+		pids[i] = int(pid)
+	}
+	return pids, nil
+}
+
+// ProcessJobs returns the job objects the process belongs to among the
+// given ones. Unlike Contains, the process is opened only once, with
+// PROCESS_QUERY_LIMITED_INFORMATION access rights.
+func ProcessJobs(pid int, jobs ...*JobObject) ([]*JobObject, error) {
+	var found []*JobObject
+	desiredAccess := jobapi.PROCESS_QUERY_LIMITED_INFORMATION
+	err := withProcessHandle(pid, desiredAccess, func(h syscall.Handle) error {
+		for _, job := range jobs {
+			ok, err := jobapi.IsProcessInJob(h, job.Handle)
+			if err != nil {
+				return err
+			}
+			if ok {
+				found = append(found, job)
+			}
+		}
+		return nil
+	})
+	return found, err
+}
+
 func withProcessHandle(pid, access int, fn func(h syscall.Handle) error) error {
 	hProcess, err := syscall.OpenProcess(uint32(access), false, uint32(pid))
 	if err != nil {
@@ -191,34 +643,177 @@ func (job *JobObject) QueryCounters(c *Counters) error {
 	if err != nil {
 		return err
 	}
+	c.fill(&job.AccountingInfo)
+	return nil
+}
 
-	c.TotalUserTime = job.AccountingInfo.TotalUserTime
-	c.TotalKernelTime = job.AccountingInfo.TotalUserTime
-	c.ThisPeriodTotalUserTime = job.AccountingInfo.TotalUserTime
-	c.ThisPeriodTotalKernelTime = job.AccountingInfo.TotalUserTime
+// QueryCountersWithMemory queries the job object for basic and I/O
+// accounting information, as well as for peak memory usage, and fills
+// provided Counters with the data retrieved. The call requires an
+// additional query of extended limit information.
+func (job *JobObject) QueryCountersWithMemory(c *Counters) error {
+	if err := job.QueryCounters(c); err != nil {
+		return err
+	}
+	err := job.sync(jobapi.QueryInfo, jobapi.JobObjectExtendedLimitInformation)
+	if err != nil {
+		return err
+	}
+	c.PeakProcessMemoryUsed = uint64(job.ExtendedLimits.PeakProcessMemoryUsed)
+	c.PeakJobMemoryUsed = uint64(job.ExtendedLimits.PeakJobMemoryUsed)
+	return nil
+}
 
-	c.TotalPageFaultCount = job.AccountingInfo.TotalPageFaultCount
-	c.TotalProcesses = job.AccountingInfo.TotalProcesses
-	c.ActiveProcesses = job.AccountingInfo.ActiveProcesses
-	c.TotalTerminatedProcesses = job.AccountingInfo.TotalTerminatedProcesses
+// QueryCountersInto queries the job object for basic and I/O accounting
+// information and fills provided Counters with the data retrieved. Unlike
+// QueryCounters, the call does not allocate and does not modify JobInfo of
+// the job object, therefore it is suitable for high-frequency sampling.
+func (job *JobObject) QueryCountersInto(buf *Counters) error {
+	if err := job.valid(); err != nil {
+		return err
+	}
+	var info jobapi.JOBOBJECT_BASIC_AND_IO_ACCOUNTING_INFORMATION
+	var retLen uint32
+	err := jobapi.QueryInformationJobObject(job.Handle,
+		jobapi.JobObjectBasicAndIoAccountingInformation,
+		unsafe.Pointer(&info),
+		uint32(unsafe.Sizeof(info)),
+		unsafe.Pointer(&retLen))
+	if err != nil {
+		return err
+	}
+	buf.fill(&info)
+	return nil
+}
 
-	c.ReadOperationCount = job.AccountingInfo.ReadOperationCount
-	c.WriteOperationCount = job.AccountingInfo.WriteOperationCount
-	c.OtherOperationCount = job.AccountingInfo.OtherOperationCount
-	c.ReadTransferCount = job.AccountingInfo.ReadTransferCount
-	c.WriteTransferCount = job.AccountingInfo.WriteTransferCount
-	c.OtherTransferCount = job.AccountingInfo.OtherOperationCount
+func (c *Counters) fill(info *jobapi.JOBOBJECT_BASIC_AND_IO_ACCOUNTING_INFORMATION) {
+	c.TotalUserTime = info.TotalUserTime
+	c.TotalKernelTime = info.TotalKernelTime
+	c.ThisPeriodTotalUserTime = info.ThisPeriodTotalUserTime
+	c.ThisPeriodTotalKernelTime = info.ThisPeriodTotalKernelTime
+
+	c.TotalPageFaultCount = info.TotalPageFaultCount
+	c.TotalProcesses = info.TotalProcesses
+	c.ActiveProcesses = info.ActiveProcesses
+	c.TotalTerminatedProcesses = info.TotalTerminatedProcesses
+
+	c.ReadOperationCount = info.ReadOperationCount
+	c.WriteOperationCount = info.WriteOperationCount
+	c.OtherOperationCount = info.OtherOperationCount
+	c.ReadTransferCount = info.ReadTransferCount
+	c.WriteTransferCount = info.WriteTransferCount
+	c.OtherTransferCount = info.OtherTransferCount
+}
 
-	return nil
+// BasicAccounting queries the job object for basic accounting information
+// only. The call is cheaper than Counters or QueryCounters and should be
+// preferred if I/O counters are not needed.
+func (job *JobObject) BasicAccounting() (*jobapi.JOBOBJECT_BASIC_ACCOUNTING_INFORMATION, error) {
+	if err := job.valid(); err != nil {
+		return nil, err
+	}
+	var info jobapi.JOBOBJECT_BASIC_ACCOUNTING_INFORMATION
+	err := jobapi.QueryInfo(job.Handle, jobapi.JobObjectBasicAccountingInformation, &info)
+	if err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// EffectiveMemoryLimit returns the amount of committed memory in bytes the
+// processes of the job can use, or 0 if the job memory is not limited.
+//
+// For nested jobs, the most restrictive limit in the job chain applies.
+// However, the OS does not expose parent jobs of a job object, therefore the
+// returned value is an approximation: only the limits of the job itself and
+// of the parent jobs known from CreateChild are considered, and the actual
+// limit may be lower if another parent job is limited.
+func (job *JobObject) EffectiveMemoryLimit() (uint64, error) {
+	err := job.sync(jobapi.QueryInfo, jobapi.JobObjectExtendedLimitInformation)
+	if err != nil {
+		return 0, err
+	}
+	var limit uint64
+	if LimitJobMemory.IsSet(job) {
+		limit = uint64(LimitJobMemory.LimitValue(job))
+	}
+	// Parent jobs are queried without updating their JobInfo.
+	for p := job.parent; p != nil; p = p.parent {
+		if err = p.valid(); err != nil {
+			return 0, err
+		}
+		var info jobapi.JOBOBJECT_EXTENDED_LIMIT_INFORMATION
+		err = jobapi.QueryInfo(p.Handle, jobapi.JobObjectExtendedLimitInformation, &info)
+		if err != nil {
+			return 0, err
+		}
+		if info.BasicLimitInformation.LimitFlags&jobapi.JOB_OBJECT_LIMIT_JOB_MEMORY == 0 {
+			continue
+		}
+		if v := uint64(info.JobMemoryLimit); limit == 0 || v < limit {
+			limit = v
+		}
+	}
+	return limit, nil
+}
+
+// PeakJobMemoryUsed returns the peak amount of memory in bytes used by all
+// processes of the job object.
+func (job *JobObject) PeakJobMemoryUsed() (uintptr, error) {
+	err := job.sync(jobapi.QueryInfo, jobapi.JobObjectExtendedLimitInformation)
+	if err != nil {
+		return 0, err
+	}
+	return job.ExtendedLimits.PeakJobMemoryUsed, nil
+}
+
+// PeakProcessMemoryUsed returns the peak amount of memory in bytes used by
+// any process ever associated with the job object.
+func (job *JobObject) PeakProcessMemoryUsed() (uintptr, error) {
+	err := job.sync(jobapi.QueryInfo, jobapi.JobObjectExtendedLimitInformation)
+	if err != nil {
+		return 0, err
+	}
+	return job.ExtendedLimits.PeakProcessMemoryUsed, nil
+}
+
+// ClearPeakJobMemoryUsed resets the peak amount of memory used by the job
+// object processes to the current usage. Note that the information class
+// JobObjectClearPeakJobMemoryUsed is not documented by Microsoft.
+func (job *JobObject) ClearPeakJobMemoryUsed() error {
+	if err := job.valid(); err != nil {
+		return err
+	}
+	return jobapi.SetInformationJobObject(job.Handle, jobapi.JobObjectClearPeakJobMemoryUsed, nil, 0)
+}
+
+// ExtendedIOInfo queries the job object for extended limit information and
+// returns its IoInfo member. The member is documented as reserved, therefore
+// the call is best-effort: the counters may be empty or differ from the I/O
+// accounting information, depending on the OS version. Prefer Counters for
+// reliable I/O accounting.
+func (job *JobObject) ExtendedIOInfo() (jobapi.IO_COUNTERS, error) {
+	err := job.sync(jobapi.QueryInfo, jobapi.JobObjectExtendedLimitInformation)
+	if err != nil {
+		return jobapi.IO_COUNTERS{}, err
+	}
+	return job.ExtendedLimits.IoInfo, nil
+}
+
+// limitInfoClasses lists all the information classes of limits managed
+// by the package.
+var limitInfoClasses = []jobapi.JobObjectInformationClass{
+	jobapi.JobObjectExtendedLimitInformation,
+	jobapi.JobObjectBasicUIRestrictions,
+	jobapi.JobObjectCpuRateControlInformation,
+	jobapi.JobObjectNetRateControlInformation,
+	jobapi.JobObjectEndOfJobTimeInformation,
+	jobapi.JobObjectNotificationLimitInformation,
 }
 
 // QueryLimits queries all supported limit information for the job object.
 func (job *JobObject) QueryLimits() error {
-	return job.sync(jobapi.QueryInfo,
-		jobapi.JobObjectExtendedLimitInformation,
-		jobapi.JobObjectBasicUIRestrictions,
-		jobapi.JobObjectCpuRateControlInformation,
-		jobapi.JobObjectNetRateControlInformation)
+	return job.sync(jobapi.QueryInfo, limitInfoClasses...)
 }
 
 // SetLimit applies given limits to the job object.
@@ -244,14 +839,58 @@ func (job *JobObject) ResetLimits() error {
 	return job.sync(jobapi.SetInfo, infoClasses...)
 }
 
+// ResetAll resets all the limit information managed by the package,
+// regardless of whether any limit is set: basic and extended limits, UI
+// restrictions, rate controls, end-of-job time action, and notification
+// limits. I/O rate control is reset for every volume it is configured for,
+// if supported by the operating system.
+func (job *JobObject) ResetAll() error {
+	if err := job.QueryLimits(); err != nil {
+		return err
+	}
+	job.JobInfo = JobInfo{}
+	if err := job.sync(jobapi.SetInfo, limitInfoClasses...); err != nil {
+		return err
+	}
+	if !SupportedFeatures().IORateControl {
+		return nil
+	}
+	return job.resetIORateControl()
+}
+
+// resetIORateControl resets I/O rate control for all the volumes.
+func (job *JobObject) resetIORateControl() error {
+	volumes, err := jobapi.QueryIoRateControlVolumes(job.Handle)
+	if err != nil {
+		return err
+	}
+	for _, volume := range volumes {
+		if err = job.ResetLimit(LimitIORateControl.WithValue(IORate{}, volume)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // ResetLimit resets given limits of the job object.
 func (job *JobObject) ResetLimit(limits ...Limit) error {
 	return job.applyLimit(false, limits...)
 }
 
 // applyLimits queries required limit information and sets or resets
-// the limits specified.
+// the limits specified. Limits to be set are validated beforehand.
 func (job *JobObject) applyLimit(set bool, limits ...Limit) error {
+	if err := job.valid(); err != nil {
+		return err
+	}
+	if set {
+		if err := validateLimits(limits...); err != nil {
+			return err
+		}
+		if err := prepareLimits(limits...); err != nil {
+			return err
+		}
+	}
 	classesSet := make(map[jobapi.JobObjectInformationClass]struct{})
 	for _, limit := range limits {
 		infoClass := resolveRequiredInfoClass(limit)
@@ -286,6 +925,12 @@ func resolveRequiredInfoClass(limit Limit) jobapi.JobObjectInformationClass {
 		return jobapi.JobObjectCpuRateControlInformation
 	case netBandwidthLimit, netDSCPTagLimit:
 		return jobapi.JobObjectNetRateControlInformation
+	case endOfJobTimeAction:
+		return jobapi.JobObjectEndOfJobTimeInformation
+	case ioRateControlLimit:
+		return jobapi.JobObjectIoRateControlInformation
+	case notifyJobMemoryLimit, notifyJobTimeLimit, notifyIoReadBytesLimit, notifyIoWriteBytesLimit:
+		return jobapi.JobObjectNotificationLimitInformation
 	}
 }
 
@@ -301,6 +946,12 @@ func (job *JobObject) infoPtr(infoClass jobapi.JobObjectInformationClass) interf
 		return &job.CPURateControl
 	case jobapi.JobObjectNetRateControlInformation:
 		return &job.NetRateControl
+	case jobapi.JobObjectIoRateControlInformation:
+		return &job.IORateControl
+	case jobapi.JobObjectEndOfJobTimeInformation:
+		return &job.EndOfJobTime
+	case jobapi.JobObjectNotificationLimitInformation:
+		return &job.NotificationLimits
 	default:
 		return nil
 	}
@@ -327,6 +978,14 @@ func (job *JobObject) limitInfoClassesSet() (classes []jobapi.JobObjectInformati
 			job.NetRateControl.ControlFlags > 0,
 			jobapi.JobObjectNetRateControlInformation,
 		},
+		{
+			job.EndOfJobTime.EndOfJobTimeAction != jobapi.JOB_OBJECT_TERMINATE_AT_END_OF_JOB,
+			jobapi.JobObjectEndOfJobTimeInformation,
+		},
+		{
+			job.NotificationLimits.LimitFlags > 0,
+			jobapi.JobObjectNotificationLimitInformation,
+		},
 	} {
 		if info.isSet {
 			classes = append(classes, info.class)
@@ -338,6 +997,9 @@ func (job *JobObject) limitInfoClassesSet() (classes []jobapi.JobObjectInformati
 type infoClassSync func(syscall.Handle, jobapi.JobObjectInformationClass, interface{}) error
 
 func (job *JobObject) sync(fn infoClassSync, infoClasses ...jobapi.JobObjectInformationClass) error {
+	if err := job.valid(); err != nil {
+		return err
+	}
 	for _, infoClass := range infoClasses {
 		if err := fn(job.Handle, infoClass, job.infoPtr(infoClass)); err != nil {
 			return err
@@ -4,12 +4,15 @@ package winjob_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
+	"strings"
 	"syscall"
 	"testing"
 	"time"
+	"unsafe"
 
 	"golang.org/x/sys/windows"
 
@@ -69,28 +72,44 @@ func requireNoError(t *testing.T, err error, msg ...string) {
 }
 
 func TestInvalidJobObjectHandle(t *testing.T) {
-	var (
-		job      = &winjob.JobObject{Handle: syscall.InvalidHandle}
-		process  = new(os.Process)
-		counters winjob.Counters
-	)
-	requireError := func(t *testing.T, err error) {
-		if err == nil {
-			t.Fatalf("Expected error, got nil")
+	for _, h := range []syscall.Handle{syscall.InvalidHandle, 0} {
+		var (
+			job      = &winjob.JobObject{Handle: h}
+			process  = new(os.Process)
+			counters winjob.Counters
+		)
+		requireError := func(t *testing.T, err error) {
+			if !errors.Is(err, winjob.ErrInvalidHandle) {
+				t.Fatalf("Expected %v, got %v", winjob.ErrInvalidHandle, err)
+			}
 		}
+
+		requireError(t, job.Assign(process))
+		requireError(t, job.Terminate())
+		_, err := job.Contains(process)
+		requireError(t, err)
+		requireError(t, job.QueryLimits())
+		requireError(t, job.QueryCounters(&counters))
+		_, err = job.HasLimits()
+		requireError(t, err)
+		requireError(t, job.ResetLimits())
+		requireError(t, job.ResetLimit(winjob.LimitBreakawayOK))
+		requireError(t, job.SetLimit(winjob.LimitCPU))
+		_, err = job.SameAs(job)
+		requireError(t, err)
+		_, err = job.SameAs(nil)
+		requireError(t, err)
 	}
+}
 
-	requireError(t, job.Assign(process))
-	requireError(t, job.Terminate())
-	_, err := job.Contains(process)
-	requireError(t, err)
-	requireError(t, job.QueryLimits())
-	requireError(t, job.QueryCounters(&counters))
-	_, err = job.HasLimits()
-	requireError(t, err)
-	requireError(t, job.ResetLimits())
-	requireError(t, job.ResetLimit(winjob.LimitBreakawayOK))
-	requireError(t, job.SetLimit(winjob.LimitCPU))
+func TestClose_Twice(t *testing.T) {
+	job, err := winjob.Create("")
+	requireNoError(t, err)
+	requireNoError(t, job.Close())
+	if job.Handle != syscall.InvalidHandle {
+		t.Fatalf("Expected invalid handle, got %v", job.Handle)
+	}
+	requireNoError(t, job.Close())
 }
 
 func TestCreateWithLimits(t *testing.T) {
@@ -105,6 +124,68 @@ func TestCreateWithLimits(t *testing.T) {
 	}
 }
 
+func TestCreateWithSecurity(t *testing.T) {
+	sa := syscall.SecurityAttributes{InheritHandle: 1}
+	sa.Length = uint32(unsafe.Sizeof(sa))
+	name := fmt.Sprintf("go-winjob-testing-%d", time.Now().UnixNano())
+	job, err := winjob.CreateWithSecurity(name, &sa, winjob.WithKillOnJobClose())
+	requireNoError(t, err)
+	defer func() {
+		requireNoError(t, job.Close())
+	}()
+	// The handle is inherited by child processes if it has
+	// HANDLE_FLAG_INHERIT flag set.
+	policy, err := job.TerminationPolicy()
+	requireNoError(t, err)
+	if policy != winjob.TerminationPolicyKillOnLastHandleCloseInherited {
+		t.Fatalf("Expected %v, got %v", winjob.TerminationPolicyKillOnLastHandleCloseInherited, policy)
+	}
+
+	// The child process is created with bInheritHandles set directly, since
+	// os/exec may restrict the handles inherited.
+	var (
+		si windows.StartupInfo
+		pi windows.ProcessInformation
+	)
+	si.Cb = uint32(unsafe.Sizeof(si))
+	cmdLine, err := windows.UTF16PtrFromString(commandName)
+	requireNoError(t, err)
+	requireNoError(t, windows.CreateProcess(nil, cmdLine, nil, nil, true, 0, nil, nil, &si, &pi))
+	defer func() {
+		_ = windows.CloseHandle(pi.Thread)
+		_ = windows.CloseHandle(pi.Process)
+	}()
+	requireNoError(t, job.AssignHandle(syscall.Handle(pi.Process)))
+
+	// The job object is kept alive by the handle inherited by the child,
+	// therefore the process is not terminated on close.
+	requireNoError(t, job.Close())
+	opened, err := winjob.Open(name)
+	requireNoError(t, err)
+	defer func() {
+		requireNoError(t, opened.Terminate())
+		requireNoError(t, opened.Close())
+	}()
+	event, err := windows.WaitForSingleObject(pi.Process, 200)
+	if event != uint32(windows.WAIT_TIMEOUT) {
+		t.Fatalf("Child process is not expected to exit: %v", err)
+	}
+}
+
+func TestCreateInvalidName(t *testing.T) {
+	for _, name := range []string{`my\job`, `Global\my\job`, `\job`} {
+		if _, err := winjob.Create(name); !errors.Is(err, winjob.ErrInvalidName) {
+			t.Fatalf("%q: expected %v, got %v", name, winjob.ErrInvalidName, err)
+		}
+		if _, err := winjob.Open(name); !errors.Is(err, winjob.ErrInvalidName) {
+			t.Fatalf("%q: expected %v, got %v", name, winjob.ErrInvalidName, err)
+		}
+	}
+	job, err := winjob.Create(fmt.Sprintf(`Local\go-winjob-testing-%d`, time.Now().UnixNano()))
+	requireNoError(t, err)
+	requireNoError(t, job.Close())
+}
+
 func TestTerminate(t *testing.T) {
 	runTestWithTestJobObjectWithProcess(t, func(job *winjob.JobObject, p *os.Process) {
 		const exitCode = 3
@@ -117,6 +198,146 @@ func TestTerminate(t *testing.T) {
 	})
 }
 
+func TestWaitSignaled(t *testing.T) {
+	runTestWithEmptyJobObject(t, func(job *winjob.JobObject) {
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+		if err := job.WaitSignaled(ctx); err != context.DeadlineExceeded {
+			t.Fatalf("Expected %v, got %v", context.DeadlineExceeded, err)
+		}
+	})
+}
+
+func TestTerminateProcess(t *testing.T) {
+	runTestWithTestJobObjectWithProcess(t, func(job *winjob.JobObject, p *os.Process) {
+		runTestWithEmptyJobObject(t, func(other *winjob.JobObject) {
+			if err := other.TerminateProcess(p.Pid, 1); err != winjob.ErrProcessNotInJob {
+				t.Fatalf("Expected %v, got %v", winjob.ErrProcessNotInJob, err)
+			}
+		})
+		const exitCode = 3
+		requireNoError(t, job.TerminateProcess(p.Pid, exitCode))
+		s, err := p.Wait()
+		requireNoError(t, err)
+		if s.ExitCode() != exitCode {
+			t.Fatalf("Expected exit code %d, got %d", exitCode, s.ExitCode())
+		}
+	})
+}
+
+func TestAssignVerified(t *testing.T) {
+	runTestWithTestJobObjectWithProcess(t, func(_ *winjob.JobObject, p *os.Process) {
+		runTestWithEmptyJobObject(t, func(other *winjob.JobObject) {
+			requireNoError(t, other.AssignVerified(p))
+			contains, err := other.Contains(p)
+			requireNoError(t, err)
+			if !contains {
+				t.Fatal("Job does not contain the process specified")
+			}
+		})
+	})
+}
+
+func TestAssignPID(t *testing.T) {
+	runTestWithEmptyJobObject(t, func(job *winjob.JobObject) {
+		cmd := winjob.SuspendedCommand(commandName)
+		requireNoError(t, cmd.Start())
+		defer func() {
+			requireNoError(t, cmd.Process.Kill())
+			_ = cmd.Wait()
+		}()
+		requireNoError(t, job.AssignPID(cmd.Process.Pid))
+		contains, err := job.Contains(cmd.Process)
+		requireNoError(t, err)
+		if !contains {
+			t.Fatal("Job does not contain the process specified")
+		}
+	})
+}
+
+func TestAssignHandle(t *testing.T) {
+	runTestWithEmptyJobObject(t, func(job *winjob.JobObject) {
+		cmd := winjob.SuspendedCommand(commandName)
+		requireNoError(t, cmd.Start())
+		defer func() {
+			requireNoError(t, cmd.Process.Kill())
+			_ = cmd.Wait()
+		}()
+		const desiredAccess = windows.PROCESS_SET_QUOTA | windows.PROCESS_TERMINATE
+		h, err := syscall.OpenProcess(desiredAccess, false, uint32(cmd.Process.Pid))
+		requireNoError(t, err)
+		defer func() {
+			requireNoError(t, syscall.CloseHandle(h))
+		}()
+		requireNoError(t, job.AssignHandle(h))
+		contains, err := job.Contains(cmd.Process)
+		requireNoError(t, err)
+		if !contains {
+			t.Fatal("Job does not contain the process specified")
+		}
+	})
+}
+
+func TestAssignWithAccess(t *testing.T) {
+	runTestWithEmptyJobObject(t, func(job *winjob.JobObject) {
+		cmd := winjob.SuspendedCommand(commandName)
+		requireNoError(t, cmd.Start())
+		defer func() {
+			requireNoError(t, cmd.Process.Kill())
+			_ = cmd.Wait()
+		}()
+		const desiredAccess = windows.PROCESS_SET_QUOTA | windows.PROCESS_TERMINATE
+		requireNoError(t, job.AssignWithAccess(cmd.Process, desiredAccess))
+		contains, err := job.Contains(cmd.Process)
+		requireNoError(t, err)
+		if !contains {
+			t.Fatal("Job does not contain the process specified")
+		}
+	})
+}
+
+// assignCurrentProcessEnv is set for the test process started by
+// TestAssignCurrentProcess: the process assigns itself to a job object,
+// which would affect other tests otherwise.
+const assignCurrentProcessEnv = "GO_WINJOB_TEST_ASSIGN_CURRENT_PROCESS"
+
+func TestAssignCurrentProcess(t *testing.T) {
+	if os.Getenv(assignCurrentProcessEnv) == "" {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestAssignCurrentProcess$")
+		cmd.Env = append(os.Environ(), assignCurrentProcessEnv+"=1")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("%v: %s", err, out)
+		}
+		return
+	}
+	runTestWithEmptyJobObject(t, func(job *winjob.JobObject) {
+		requireNoError(t, job.AssignCurrentProcess())
+		p, err := os.FindProcess(os.Getpid())
+		requireNoError(t, err)
+		contains, err := job.Contains(p)
+		requireNoError(t, err)
+		if !contains {
+			t.Fatal("Job does not contain the current process")
+		}
+	})
+}
+
+func TestAssign_ProcessAlreadyInJob(t *testing.T) {
+	runTestWithTestJobObjectWithProcess(t, func(_ *winjob.JobObject, p *os.Process) {
+		// The other job has a process that does not belong to the first
+		// job, therefore the jobs can not be nested.
+		runTestWithTestJobObjectWithProcess(t, func(other *winjob.JobObject, _ *os.Process) {
+			err := other.Assign(p)
+			if !errors.Is(err, winjob.ErrProcessAlreadyInJob) {
+				t.Fatalf("Expected %v, got %v", winjob.ErrProcessAlreadyInJob, err)
+			}
+			if !errors.Is(err, windows.ERROR_ACCESS_DENIED) {
+				t.Fatalf("Expected %v in the error chain, got %v", windows.ERROR_ACCESS_DENIED, err)
+			}
+		})
+	})
+}
+
 func TestContainsProcess(t *testing.T) {
 	runTestWithTestJobObjectWithProcess(t, func(job *winjob.JobObject, p *os.Process) {
 		contains, err := job.Contains(p)
@@ -127,6 +348,97 @@ func TestContainsProcess(t *testing.T) {
 	})
 }
 
+func TestForEachProcess(t *testing.T) {
+	runTestWithTestJobObjectWithProcess(t, func(job *winjob.JobObject, p *os.Process) {
+		var found bool
+		requireNoError(t, job.ForEachProcess(func(pid int) error {
+			found = found || pid == p.Pid
+			return nil
+		}))
+		if !found {
+			t.Fatal("Process not found")
+		}
+		errStop := errors.New("stop")
+		if err := job.ForEachProcess(func(int) error { return errStop }); err != errStop {
+			t.Fatalf("Expected %v, got %v", errStop, err)
+		}
+	})
+}
+
+func TestProcessIDs(t *testing.T) {
+	runTestWithEmptyJobObject(t, func(job *winjob.JobObject) {
+		defer func() {
+			requireNoError(t, job.Terminate())
+		}()
+		expected := make(map[int]bool)
+		for i := 0; i < 3; i++ {
+			cmd := exec.Command(commandName)
+			requireNoError(t, winjob.StartInJobObject(cmd, job))
+			expected[cmd.Process.Pid] = true
+		}
+		pids, err := job.ProcessIDs()
+		requireNoError(t, err)
+		for _, pid := range pids {
+			delete(expected, pid)
+		}
+		if len(expected) != 0 {
+			t.Fatalf("Processes not found: %v", expected)
+		}
+	})
+}
+
+func TestAssignTree(t *testing.T) {
+	runTestWithEmptyJobObject(t, func(job *winjob.JobObject) {
+		// The parent process waits for its child.
+		cmd := exec.Command("cmd.exe", "/c", "ping", "-n", "30", "127.0.0.1")
+		requireNoError(t, cmd.Start())
+		defer func() {
+			requireNoError(t, job.Terminate())
+			_ = cmd.Wait()
+		}()
+		// The child process is created asynchronously.
+		deadline := time.Now().Add(jobTestTimeout)
+		for {
+			requireNoError(t, job.AssignTree(cmd.Process.Pid))
+			info, err := job.BasicAccounting()
+			requireNoError(t, err)
+			if info.ActiveProcesses >= 2 {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("Expected at least 2 active processes, got %d", info.ActiveProcesses)
+			}
+			time.Sleep(time.Millisecond * 50)
+		}
+	})
+}
+
+func TestAssignTree_Error(t *testing.T) {
+	runTestWithEmptyJobObject(t, func(job *winjob.JobObject) {
+		// PID 0 is the System Idle Process, which can not be opened.
+		err := job.AssignTree(0)
+		var treeErr *winjob.AssignTreeError
+		if !errors.As(err, &treeErr) {
+			t.Fatalf("Expected *AssignTreeError, got %v", err)
+		}
+		if _, ok := treeErr.Errors[0]; !ok {
+			t.Fatalf("Expected an error for process 0, got %v", treeErr)
+		}
+	})
+}
+
+func TestProcessJobs(t *testing.T) {
+	runTestWithTestJobObjectWithProcess(t, func(job *winjob.JobObject, p *os.Process) {
+		runTestWithEmptyJobObject(t, func(other *winjob.JobObject) {
+			jobs, err := winjob.ProcessJobs(p.Pid, other, job)
+			requireNoError(t, err)
+			if len(jobs) != 1 || jobs[0] != job {
+				t.Fatalf("Unexpected jobs: %v", jobs)
+			}
+		})
+	})
+}
+
 func TestOpenJobObject(t *testing.T) {
 	runTestWithTestJobObjectWithProcess(t, func(job *winjob.JobObject, _ *os.Process) {
 		_, err := winjob.Open(job.Name)
@@ -134,6 +446,134 @@ func TestOpenJobObject(t *testing.T) {
 	})
 }
 
+func TestOpenForQuery(t *testing.T) {
+	runTestWithEmptyJobObject(t, func(job *winjob.JobObject) {
+		requireNoError(t, job.SetLimit(winjob.WithKillOnJobClose()))
+		opened, err := winjob.OpenForQuery(job.Name)
+		requireNoError(t, err)
+		defer func() {
+			requireNoError(t, opened.Close())
+		}()
+		requireNoError(t, opened.QueryLimits())
+		if !winjob.LimitKillOnJobClose.IsSet(opened) {
+			t.Fatal("Job object limit is not set")
+		}
+		if err = opened.SetLimit(winjob.WithBreakawayOK()); err == nil {
+			t.Fatal("Expected access denied error")
+		}
+	})
+	_, err := winjob.OpenForQuery(fmt.Sprintf("go-winjob-testing-missing-%d", time.Now().UnixNano()))
+	if !errors.Is(err, winjob.ErrJobNotExist) {
+		t.Fatalf("Expected %v, got %v", winjob.ErrJobNotExist, err)
+	}
+}
+
+func TestTerminationPolicy(t *testing.T) {
+	runTestWithEmptyJobObject(t, func(job *winjob.JobObject) {
+		policy, err := job.TerminationPolicy()
+		requireNoError(t, err)
+		if policy != winjob.TerminationPolicyNone {
+			t.Fatalf("Expected %v, got %v", winjob.TerminationPolicyNone, policy)
+		}
+		requireNoError(t, job.SetLimit(winjob.WithKillOnJobClose()))
+		policy, err = job.TerminationPolicy()
+		requireNoError(t, err)
+		if policy != winjob.TerminationPolicyKillOnLastHandleClose {
+			t.Fatalf("Expected %v, got %v", winjob.TerminationPolicyKillOnLastHandleClose, policy)
+		}
+	})
+}
+
+func TestQueryName(t *testing.T) {
+	runTestWithEmptyJobObject(t, func(job *winjob.JobObject) {
+		name, err := job.QueryName()
+		requireNoError(t, err)
+		if !strings.HasSuffix(name, `\`+job.Name) {
+			t.Fatalf("Unexpected job object name: %q", name)
+		}
+	})
+	job, err := winjob.Create("")
+	requireNoError(t, err)
+	defer func() {
+		requireNoError(t, job.Close())
+	}()
+	name, err := job.QueryName()
+	requireNoError(t, err)
+	if name != "" {
+		t.Fatalf("Expected empty name, got %q", name)
+	}
+}
+
+func TestCreateLabeled(t *testing.T) {
+	const label = "go-winjob-testing-label"
+	job, err := winjob.CreateLabeled(label)
+	requireNoError(t, err)
+	defer func() {
+		requireNoError(t, job.Close())
+	}()
+	if job.Name != label {
+		t.Fatalf("Expected name %q, got %q", label, job.Name)
+	}
+	name, err := job.QueryName()
+	requireNoError(t, err)
+	if name != "" {
+		t.Fatalf("Expected anonymous job object, got %q", name)
+	}
+}
+
+func TestSameAs(t *testing.T) {
+	runTestWithEmptyJobObject(t, func(job *winjob.JobObject) {
+		opened, err := winjob.Open(job.Name)
+		requireNoError(t, err)
+		defer func() {
+			requireNoError(t, opened.Close())
+		}()
+		same, err := job.SameAs(opened)
+		requireNoError(t, err)
+		if !same {
+			t.Fatal("Expected job objects to be the same")
+		}
+		runTestWithEmptyJobObject(t, func(other *winjob.JobObject) {
+			same, err := job.SameAs(other)
+			requireNoError(t, err)
+			if same {
+				t.Fatal("Expected job objects to differ")
+			}
+		})
+	})
+}
+
+func TestReopen(t *testing.T) {
+	runTestWithEmptyJobObject(t, func(job *winjob.JobObject) {
+		requireNoError(t, job.SetLimit(winjob.WithKillOnJobClose()))
+		// The handle keeps the job object alive while it is being reopened.
+		opened, err := winjob.Open(job.Name)
+		requireNoError(t, err)
+		defer func() {
+			requireNoError(t, opened.Close())
+		}()
+		requireNoError(t, job.Close())
+		requireNoError(t, job.Reopen())
+		same, err := job.SameAs(opened)
+		requireNoError(t, err)
+		if !same {
+			t.Fatal("Expected job objects to be the same")
+		}
+		requireNoError(t, job.QueryLimits())
+		if !winjob.LimitKillOnJobClose.IsSet(job) {
+			t.Fatal("Job object limit is not set")
+		}
+	})
+	job, err := winjob.CreateLabeled("go-winjob-testing-label")
+	requireNoError(t, err)
+	defer func() {
+		requireNoError(t, job.Close())
+	}()
+	if err = job.Reopen(); !errors.Is(err, winjob.ErrAnonymousJob) {
+		t.Fatalf("Expected %v, got %v", winjob.ErrAnonymousJob, err)
+	}
+}
+
 func TestOpenNonexistentJobObject(t *testing.T) {
 	if _, err := winjob.Open(time.Now().String()); err == nil {
 		t.Fatal("Open: expected error, got nil")
@@ -150,3 +590,160 @@ func TestCounters(t *testing.T) {
 		}
 	})
 }
+
+func TestIsSilo(t *testing.T) {
+	runTestWithEmptyJobObject(t, func(job *winjob.JobObject) {
+		silo, err := job.IsSilo()
+		if errors.Is(err, winjob.ErrNotSupported) {
+			t.Skip(err)
+		}
+		requireNoError(t, err)
+		if silo {
+			t.Fatal("Job object is not expected to be a silo")
+		}
+	})
+}
+
+func TestSharedCommit(t *testing.T) {
+	runTestWithTestJobObjectWithProcess(t, func(job *winjob.JobObject, _ *os.Process) {
+		_, err := job.SharedCommit()
+		if errors.Is(err, winjob.ErrNotSupported) {
+			t.Skip(err)
+		}
+		requireNoError(t, err)
+	})
+}
+
+func TestIOAttribution(t *testing.T) {
+	runTestWithTestJobObjectWithProcess(t, func(job *winjob.JobObject, _ *os.Process) {
+		_, err := job.IOAttribution()
+		if errors.Is(err, winjob.ErrNotSupported) {
+			t.Skip(err)
+		}
+		requireNoError(t, err)
+	})
+}
+
+func TestServerSiloUserSharedData(t *testing.T) {
+	runTestWithEmptyJobObject(t, func(job *winjob.JobObject) {
+		_, err := job.ServerSiloUserSharedData()
+		if errors.Is(err, winjob.ErrNotSupported) {
+			t.Skip(err)
+		}
+		if !errors.Is(err, winjob.ErrNotServerSilo) {
+			t.Fatalf("Expected %v, got %v", winjob.ErrNotServerSilo, err)
+		}
+	})
+}
+
+func TestSetMemoryPartition(t *testing.T) {
+	runTestWithEmptyJobObject(t, func(job *winjob.JobObject) {
+		err := job.SetMemoryPartition(syscall.InvalidHandle)
+		if errors.Is(err, winjob.ErrNotSupported) {
+			t.Skip(err)
+		}
+		if err == nil {
+			t.Fatal("Expected error for an invalid partition handle")
+		}
+	})
+}
+
+func TestSiloRootDirectory(t *testing.T) {
+	runTestWithEmptyJobObject(t, func(job *winjob.JobObject) {
+		_, err := job.SiloRootDirectory()
+		if errors.Is(err, winjob.ErrNotSupported) {
+			t.Skip(err)
+		}
+		if err == nil {
+			t.Fatal("Expected error for a job object that is not a silo")
+		}
+	})
+}
+
+func TestQueryCountersWithMemory(t *testing.T) {
+	runTestWithTestJobObjectWithProcess(t, func(job *winjob.JobObject, _ *os.Process) {
+		var counters winjob.Counters
+		requireNoError(t, job.QueryCountersWithMemory(&counters))
+		if counters.PeakJobMemoryUsed == 0 || counters.PeakProcessMemoryUsed == 0 {
+			t.Fatal("Empty peak memory usage")
+		}
+	})
+}
+
+func TestPeakMemoryUsed(t *testing.T) {
+	runTestWithTestJobObjectWithProcess(t, func(job *winjob.JobObject, _ *os.Process) {
+		peakProcess, err := job.PeakProcessMemoryUsed()
+		requireNoError(t, err)
+		peakJob, err := job.PeakJobMemoryUsed()
+		requireNoError(t, err)
+		if peakJob == 0 || peakProcess == 0 {
+			t.Fatal("Empty peak memory usage")
+		}
+		requireNoError(t, job.ClearPeakJobMemoryUsed())
+		cleared, err := job.PeakJobMemoryUsed()
+		requireNoError(t, err)
+		if cleared > peakJob {
+			t.Fatalf("Peak job memory usage is not cleared: %d -> %d", peakJob, cleared)
+		}
+	})
+}
+
+func TestBasicAccounting(t *testing.T) {
+	runTestWithTestJobObjectWithProcess(t, func(job *winjob.JobObject, _ *os.Process) {
+		info, err := job.BasicAccounting()
+		requireNoError(t, err)
+		if info.ActiveProcesses == 0 {
+			t.Fatal("Empty accounting information")
+		}
+	})
+}
+
+func TestQueryCountersInto(t *testing.T) {
+	runTestWithTestJobObjectWithProcess(t, func(job *winjob.JobObject, _ *os.Process) {
+		var counters winjob.Counters
+		requireNoError(t, job.QueryCountersInto(&counters))
+		if counters.ActiveProcesses == 0 {
+			t.Fatal("Empty counters")
+		}
+	})
+}
+
+func BenchmarkQueryCountersInto(b *testing.B) {
+	job, err := newTestJobObject()
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer job.Close()
+	var counters winjob.Counters
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := job.QueryCountersInto(&counters); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestEffectiveMemoryLimit(t *testing.T) {
+	runTestWithEmptyJobObject(t, func(job *winjob.JobObject) {
+		limit, err := job.EffectiveMemoryLimit()
+		requireNoError(t, err)
+		if limit != 0 {
+			t.Fatalf("Expected no limit, got %d", limit)
+		}
+		const x = 10 << 20
+		requireNoError(t, job.SetLimit(winjob.WithJobMemoryLimit(x)))
+		limit, err = job.EffectiveMemoryLimit()
+		requireNoError(t, err)
+		if limit != x {
+			t.Fatalf("Expected %d, got %d", x, limit)
+		}
+	})
+}
+
+func TestExtendedIOInfo(t *testing.T) {
+	runTestWithTestJobObjectWithProcess(t, func(job *winjob.JobObject, _ *os.Process) {
+		_, err := job.ExtendedIOInfo()
+		requireNoError(t, err)
+	})
+}
@@ -3,21 +3,42 @@
 package jobapi
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"reflect"
+	"strings"
 	"syscall"
 	"unsafe"
+
+	"golang.org/x/sys/windows"
 )
 
+// DLLs are loaded from the system directory only, in order to prevent
+// DLL preloading attacks.
 var (
-	modKernel32               = syscall.NewLazyDLL("kernel32.dll")
-	openJobObject             = modKernel32.NewProc("OpenJobObjectW")
-	createJobObject           = modKernel32.NewProc("CreateJobObjectW")
-	terminateJobObject        = modKernel32.NewProc("TerminateJobObject")
-	isProcessInJob            = modKernel32.NewProc("IsProcessInJob")
-	assignProcessToJobObject  = modKernel32.NewProc("AssignProcessToJobObject")
-	setInformationJobObject   = modKernel32.NewProc("SetInformationJobObject")
-	queryInformationJobObject = modKernel32.NewProc("QueryInformationJobObject")
+	modKernel32                = windows.NewLazySystemDLL("kernel32.dll")
+	openJobObject              = modKernel32.NewProc("OpenJobObjectW")
+	createJobObject            = modKernel32.NewProc("CreateJobObjectW")
+	terminateJobObject         = modKernel32.NewProc("TerminateJobObject")
+	isProcessInJob             = modKernel32.NewProc("IsProcessInJob")
+	assignProcessToJobObject   = modKernel32.NewProc("AssignProcessToJobObject")
+	setInformationJobObject    = modKernel32.NewProc("SetInformationJobObject")
+	queryInformationJobObject  = modKernel32.NewProc("QueryInformationJobObject")
+	postQueuedCompletionStatus = modKernel32.NewProc("PostQueuedCompletionStatus")
+	getHandleInformation       = modKernel32.NewProc("GetHandleInformation")
+
+	// I/O rate control functions are available starting with Windows 10.
+	setIoRateControlInformationJobObject   = modKernel32.NewProc("SetIoRateControlInformationJobObject")
+	queryIoRateControlInformationJobObject = modKernel32.NewProc("QueryIoRateControlInformationJobObject")
+	freeMemoryJobObject                    = modKernel32.NewProc("FreeMemoryJobObject")
+
+	modKernelBase        = windows.NewLazySystemDLL("kernelbase.dll")
+	compareObjectHandles = modKernelBase.NewProc("CompareObjectHandles")
+
+	modNtdll              = windows.NewLazySystemDLL("ntdll.dll")
+	ntQueryObject         = modNtdll.NewProc("NtQueryObject")
+	rtlNtStatusToDosError = modNtdll.NewProc("RtlNtStatusToDosError")
 )
 
 // ErrAbandoned specifies that the completion port handle had been closed
@@ -72,6 +93,26 @@ const (
 	PROCESS_MODE_BACKGROUND_END   PriorityClass = 0x00200000
 )
 
+var priorityClassNames = map[PriorityClass]string{
+	NORMAL_PRIORITY_CLASS:         "NORMAL_PRIORITY_CLASS",
+	IDLE_PRIORITY_CLASS:           "IDLE_PRIORITY_CLASS",
+	HIGH_PRIORITY_CLASS:           "HIGH_PRIORITY_CLASS",
+	REALTIME_PRIORITY_CLASS:       "REALTIME_PRIORITY_CLASS",
+	BELOW_NORMAL_PRIORITY_CLASS:   "BELOW_NORMAL_PRIORITY_CLASS",
+	ABOVE_NORMAL_PRIORITY_CLASS:   "ABOVE_NORMAL_PRIORITY_CLASS",
+	PROCESS_MODE_BACKGROUND_BEGIN: "PROCESS_MODE_BACKGROUND_BEGIN",
+	PROCESS_MODE_BACKGROUND_END:   "PROCESS_MODE_BACKGROUND_END",
+}
+
+// String returns the name of the priority class, or "unknown(0xNN)" if the
+// value has no name.
+func (c PriorityClass) String() string {
+	if name, ok := priorityClassNames[c]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown(0x%02x)", uint32(c))
+}
+
 // JobObjectInformationClass is an information class for the limits to be set or queried.
 //
 // https://docs.microsoft.com/en-us/windows/desktop/api/jobapi2/nf-jobapi2-setinformationjobobject
@@ -129,6 +170,67 @@ const (
 	JobObjectThreadImpersonationInformation
 )
 
+// informationClassNames lists information class names, starting with
+// JobObjectBasicAccountingInformation.
+var informationClassNames = [...]string{
+	"JobObjectBasicAccountingInformation",
+	"JobObjectBasicLimitInformation",
+	"JobObjectBasicProcessIdList",
+	"JobObjectBasicUIRestrictions",
+	"JobObjectSecurityLimitInformation",
+	"JobObjectEndOfJobTimeInformation",
+	"JobObjectAssociateCompletionPortInformation",
+	"JobObjectBasicAndIoAccountingInformation",
+	"JobObjectExtendedLimitInformation",
+	"JobObjectJobSetInformation",
+	"JobObjectGroupInformation",
+	"JobObjectNotificationLimitInformation",
+	"JobObjectLimitViolationInformation",
+	"JobObjectGroupInformationEx",
+	"JobObjectCpuRateControlInformation",
+	"JobObjectCompletionFilter",
+	"JobObjectCompletionCounter",
+	"JobObjectFreezeInformation",
+	"JobObjectExtendedAccountingInformation",
+	"JobObjectWakeInformation",
+	"JobObjectBackgroundInformation",
+	"JobObjectSchedulingRankBiasInformation",
+	"JobObjectTimerVirtualizationInformation",
+	"JobObjectCycleTimeNotification",
+	"JobObjectClearEvent",
+	"JobObjectInterferenceInformation",
+	"JobObjectClearPeakJobMemoryUsed",
+	"JobObjectMemoryUsageInformation",
+	"JobObjectSharedCommit",
+	"JobObjectContainerId",
+	"JobObjectIoRateControlInformation",
+	"JobObjectNetRateControlInformation",
+	"JobObjectNotificationLimitInformation2",
+	"JobObjectLimitViolationInformation2",
+	"JobObjectCreateSilo",
+	"JobObjectSiloBasicInformation",
+	"JobObjectSiloRootDirectory",
+	"JobObjectServerSiloBasicInformation",
+	"JobObjectServerSiloUserSharedData",
+	"JobObjectServerSiloInitialize",
+	"JobObjectServerSiloRunningState",
+	"JobObjectIoAttribution",
+	"JobObjectMemoryPartitionInformation",
+	"JobObjectContainerTelemetryId",
+	"JobObjectSiloSystemRoot",
+	"JobObjectEnergyTrackingState",
+	"JobObjectThreadImpersonationInformation",
+}
+
+// String returns the name of the information class, or "unknown(0xNN)" if
+// the value has no name.
+func (c JobObjectInformationClass) String() string {
+	if c >= JobObjectBasicAccountingInformation && int(c) <= len(informationClassNames) {
+		return informationClassNames[c-1]
+	}
+	return fmt.Sprintf("unknown(0x%02x)", uint32(c))
+}
+
 // LimitFlag specifies the limit flags that are in effect. This type is a
 // bitfield that defines whether other structure members of JOBOBJECT_BASIC_LIMIT_INFORMATION
 // are used.
@@ -165,6 +267,51 @@ const (
 	JOB_OBJECT_LIMIT_JOB_MEMORY_HIGH  = JOB_OBJECT_LIMIT_JOB_MEMORY
 )
 
+// limitFlagNames lists limit flag names in the bit order. Aliases, such as
+// JOB_OBJECT_LIMIT_CPU_RATE_CONTROL, are not included.
+var limitFlagNames = [...]string{
+	"JOB_OBJECT_LIMIT_WORKINGSET",
+	"JOB_OBJECT_LIMIT_PROCESS_TIME",
+	"JOB_OBJECT_LIMIT_JOB_TIME",
+	"JOB_OBJECT_LIMIT_ACTIVE_PROCESS",
+	"JOB_OBJECT_LIMIT_AFFINITY",
+	"JOB_OBJECT_LIMIT_PRIORITY_CLASS",
+	"JOB_OBJECT_LIMIT_PRESERVE_JOB_TIME",
+	"JOB_OBJECT_LIMIT_SCHEDULING_CLASS",
+	"JOB_OBJECT_LIMIT_PROCESS_MEMORY",
+	"JOB_OBJECT_LIMIT_JOB_MEMORY",
+	"JOB_OBJECT_LIMIT_DIE_ON_UNHANDLED_EXCEPTION",
+	"JOB_OBJECT_LIMIT_BREAKAWAY_OK",
+	"JOB_OBJECT_LIMIT_SILENT_BREAKAWAY_OK",
+	"JOB_OBJECT_LIMIT_KILL_ON_JOB_CLOSE",
+	"JOB_OBJECT_LIMIT_SUBSET_AFFINITY",
+	"JOB_OBJECT_LIMIT_JOB_MEMORY_LOW",
+	"JOB_OBJECT_LIMIT_JOB_READ_BYTES",
+	"JOB_OBJECT_LIMIT_JOB_WRITE_BYTES",
+	"JOB_OBJECT_LIMIT_RATE_CONTROL",
+	"JOB_OBJECT_LIMIT_IO_RATE_CONTROL",
+	"JOB_OBJECT_LIMIT_NET_RATE_CONTROL",
+}
+
+// String returns names of the flags set, joined with '|'. Unknown bits are
+// formatted as a hexadecimal number.
+func (f LimitFlag) String() string {
+	if f == 0 {
+		return "0"
+	}
+	var names []string
+	for i, name := range limitFlagNames {
+		if bit := LimitFlag(1) << uint(i); f&bit != 0 {
+			names = append(names, name)
+			f &^= bit
+		}
+	}
+	if f != 0 {
+		names = append(names, fmt.Sprintf("0x%x", uint32(f)))
+	}
+	return strings.Join(names, "|")
+}
+
 // UIRestrictionsClass is a restriction class for the user interface.
 //
 // https://docs.microsoft.com/en-us/windows/desktop/api/winnt/ns-winnt-jobobject_basic_ui_restrictions
@@ -205,7 +352,7 @@ const (
 	JOB_OBJECT_MSG_END_OF_PROCESS_TIME
 	JOB_OBJECT_MSG_ACTIVE_PROCESS_LIMIT
 	JOB_OBJECT_MSG_ACTIVE_PROCESS_ZERO
-	_
+	_ // 5 is reserved.
 	JOB_OBJECT_MSG_NEW_PROCESS
 	JOB_OBJECT_MSG_EXIT_PROCESS
 	JOB_OBJECT_MSG_ABNORMAL_EXIT_PROCESS
@@ -313,6 +460,39 @@ type IO_COUNTERS struct {
 	OtherTransferCount  uint64
 }
 
+// Add returns the sum of the counters.
+func (a IO_COUNTERS) Add(b IO_COUNTERS) IO_COUNTERS {
+	return IO_COUNTERS{
+		ReadOperationCount:  a.ReadOperationCount + b.ReadOperationCount,
+		WriteOperationCount: a.WriteOperationCount + b.WriteOperationCount,
+		OtherOperationCount: a.OtherOperationCount + b.OtherOperationCount,
+		ReadTransferCount:   a.ReadTransferCount + b.ReadTransferCount,
+		WriteTransferCount:  a.WriteTransferCount + b.WriteTransferCount,
+		OtherTransferCount:  a.OtherTransferCount + b.OtherTransferCount,
+	}
+}
+
+// Sub returns the difference of the counters. Each counter is clamped to
+// zero, if the value of b exceeds the value of a, e.g. when the counters are
+// taken from different job objects.
+func (a IO_COUNTERS) Sub(b IO_COUNTERS) IO_COUNTERS {
+	return IO_COUNTERS{
+		ReadOperationCount:  subClamp(a.ReadOperationCount, b.ReadOperationCount),
+		WriteOperationCount: subClamp(a.WriteOperationCount, b.WriteOperationCount),
+		OtherOperationCount: subClamp(a.OtherOperationCount, b.OtherOperationCount),
+		ReadTransferCount:   subClamp(a.ReadTransferCount, b.ReadTransferCount),
+		WriteTransferCount:  subClamp(a.WriteTransferCount, b.WriteTransferCount),
+		OtherTransferCount:  subClamp(a.OtherTransferCount, b.OtherTransferCount),
+	}
+}
+
+func subClamp(a, b uint64) uint64 {
+	if b > a {
+		return 0
+	}
+	return a - b
+}
+
 // JOBOBJECT_BASIC_PROCESS_ID_LIST contains the process identifier list for
 // a job object. If the job is nested, the process identifier list consists
 // of all processes associated with the job and its child jobs.
@@ -324,6 +504,35 @@ type JOBOBJECT_BASIC_PROCESS_ID_LIST struct {
 	ProcessIDList             uintptr
 }
 
+// processIDListOffset is the offset of the ProcessIDList array, in uintptr
+// words. The offset is arch-dependent: it takes 2 words on 386, but only
+// 1 word on amd64.
+const processIDListOffset = unsafe.Offsetof(JOBOBJECT_BASIC_PROCESS_ID_LIST{}.ProcessIDList) /
+	unsafe.Sizeof(uintptr(0))
+
+// QueryProcessIDList queries the process identifier list of the job object.
+// The list has variable length, therefore the query is repeated with
+// a larger buffer as long as the list does not fit.
+func QueryProcessIDList(hJobObject syscall.Handle) ([]uintptr, error) {
+	n := uintptr(64)
+	for {
+		buf := make([]uintptr, processIDListOffset+n)
+		err := QueryInformationJobObject(hJobObject, JobObjectBasicProcessIdList,
+			unsafe.Pointer(&buf[0]),
+			uint32(uintptr(len(buf))*unsafe.Sizeof(buf[0])),
+			nil)
+		if err != nil && !errors.Is(err, syscall.ERROR_MORE_DATA) {
+			return nil, err
+		}
+		list := (*JOBOBJECT_BASIC_PROCESS_ID_LIST)(unsafe.Pointer(&buf[0]))
+		if err == nil && list.NumberOfAssignedProcesses <= list.NumberOfProcessIdsInList {
+			return buf[processIDListOffset : processIDListOffset+uintptr(list.NumberOfProcessIdsInList)], nil
+		}
+		// New processes may be assigned between the calls.
+		n = uintptr(list.NumberOfAssignedProcesses) * 2
+	}
+}
+
 // JOBOBJECT_CPU_RATE_CONTROL_INFORMATION contains CPU rate control information
 // for a job object. The original structure contains a union that was replaced
 // with a single Value member.
@@ -437,6 +646,182 @@ type JOBOBJECT_LIMIT_VIOLATION_INFORMATION_2 struct {
 	NetRateControlToleranceLimit JOBOBJECT_RATE_CONTROL_TOLERANCE
 }
 
+// ObjectInformationClass is an information class of a kernel object.
+//
+// https://docs.microsoft.com/en-us/windows/win32/api/winternl/nf-winternl-ntqueryobject
+type ObjectInformationClass uint32
+
+// Object information classes.
+const (
+	ObjectBasicInformation ObjectInformationClass = iota
+	ObjectNameInformation
+	ObjectTypeInformation
+)
+
+// UNICODE_STRING is used to define Unicode strings.
+//
+// https://docs.microsoft.com/en-us/windows/win32/api/ntdef/ns-ntdef-_unicode_string
+type UNICODE_STRING struct {
+	Length        uint16
+	MaximumLength uint16
+	Buffer        *uint16
+}
+
+// String converts UNICODE_STRING to a string.
+func (u UNICODE_STRING) String() string {
+	if u.Buffer == nil || u.Length == 0 {
+		return ""
+	}
+	n := int(u.Length / 2)
+	return syscall.UTF16ToString((*[1 << 29]uint16)(unsafe.Pointer(u.Buffer))[:n:n])
+}
+
+// OBJECT_NAME_INFORMATION contains the name of a kernel object. The buffer
+// of the name follows the structure.
+type OBJECT_NAME_INFORMATION struct {
+	Name UNICODE_STRING
+}
+
+// FreezeFlag specifies the operations performed with JobObjectFreezeInformation
+// information class. The structure is not documented by Microsoft.
+type FreezeFlag uint32
+
+// Job object freeze operation flags.
+const (
+	FreezeOperation FreezeFlag = 1 << iota
+	FilterOperation
+	SwapOperation
+)
+
+// JOBOBJECT_WAKE_FILTER is a wake filter of a frozen job object.
+// The structure is not documented by Microsoft.
+type JOBOBJECT_WAKE_FILTER struct {
+	HighEdgeFilter uint32
+	LowEdgeFilter  uint32
+}
+
+// JOBOBJECT_FREEZE_INFORMATION contains freeze information for a job object.
+// The structure is not documented by Microsoft, and is used with
+// JobObjectFreezeInformation information class.
+type JOBOBJECT_FREEZE_INFORMATION struct {
+	Flags      FreezeFlag
+	Freeze     bool
+	Swap       bool
+	_          [2]byte
+	WakeFilter JOBOBJECT_WAKE_FILTER
+}
+
+// IoRateControlFlag specifies I/O rate control options.
+//
+// https://docs.microsoft.com/en-us/windows/win32/api/jobapi2/ns-jobapi2-jobobject_io_rate_control_information
+type IoRateControlFlag uint32
+
+// I/O rate control flags.
+const (
+	JOB_OBJECT_IO_RATE_CONTROL_ENABLE IoRateControlFlag = 1 << iota
+	JOB_OBJECT_IO_RATE_CONTROL_STANDALONE_VOLUME
+	JOB_OBJECT_IO_RATE_CONTROL_FORCE_UNIT_ACCESS_ALL
+	JOB_OBJECT_IO_RATE_CONTROL_FORCE_UNIT_ACCESS_ON_SOFT_CAP
+)
+
+// IoAttributionControlFlag specifies I/O attribution control options.
+type IoAttributionControlFlag uint32
+
+// I/O attribution control flags.
+const (
+	JOBOBJECT_IO_ATTRIBUTION_CONTROL_ENABLE IoAttributionControlFlag = 1 << iota
+	JOBOBJECT_IO_ATTRIBUTION_CONTROL_DISABLE
+	JOBOBJECT_IO_ATTRIBUTION_CONTROL_VALID_FLAGS IoAttributionControlFlag = 3
+)
+
+// JOBOBJECT_SHARED_COMMIT contains the amount of shared committed memory
+// attributed to a job object. The structure is not documented by Microsoft,
+// and is used with JobObjectSharedCommit information class.
+type JOBOBJECT_SHARED_COMMIT struct {
+	SharedCommitCharge uint64
+}
+
+// JOBOBJECT_MEMORY_PARTITION_INFORMATION specifies the memory partition of
+// a job object. The structure is not documented by Microsoft, and is used
+// with JobObjectMemoryPartitionInformation information class.
+type JOBOBJECT_MEMORY_PARTITION_INFORMATION struct {
+	Partition syscall.Handle
+}
+
+// SILOOBJECT_BASIC_INFORMATION contains basic information about a silo.
+//
+// https://docs.microsoft.com/en-us/windows/win32/api/winnt/ns-winnt-silo_object_basic_information
+type SILOOBJECT_BASIC_INFORMATION struct {
+	SiloID            uint32
+	SiloParentID      uint32
+	NumberOfProcesses uint32
+	IsInServerSilo    bool
+	_                 [3]byte
+}
+
+// SILOOBJECT_ROOT_DIRECTORY contains the root directory of a silo. The
+// original structure contains a union of ControlFlags and Path members that
+// was replaced with a single Path member. When queried, the buffer of the
+// path follows the structure.
+//
+// https://docs.microsoft.com/en-us/windows/win32/api/winnt/ns-winnt-siloobject_root_directory
+type SILOOBJECT_ROOT_DIRECTORY struct {
+	Path UNICODE_STRING
+}
+
+// QuerySiloRootDirectory returns the root directory of the silo.
+func QuerySiloRootDirectory(hJobObject syscall.Handle) (string, error) {
+	var retLen uint32
+	b := make([]byte, 512)
+	for {
+		err := QueryInformationJobObject(hJobObject, JobObjectSiloRootDirectory,
+			unsafe.Pointer(&b[0]),
+			uint32(len(b)),
+			unsafe.Pointer(&retLen))
+		if err == nil {
+			break
+		}
+		if int(retLen) <= len(b) {
+			return "", err
+		}
+		b = make([]byte, retLen)
+	}
+	return (*SILOOBJECT_ROOT_DIRECTORY)(unsafe.Pointer(&b[0])).Path.String(), nil
+}
+
+// SILO_USER_SHARED_DATA contains the server silo view of the system data
+// shared with user mode (KUSER_SHARED_DATA), such as the system root. The
+// structure is not documented by Microsoft, and is used with
+// JobObjectServerSiloUserSharedData information class. Later versions of the
+// OS append members to the structure: only the common ones are defined.
+type SILO_USER_SHARED_DATA struct {
+	ServiceSessionID                  uint32
+	ActiveConsoleID                   uint32
+	ConsoleSessionForegroundProcessID int64
+	NtProductType                     uint32
+	SuiteMask                         uint32
+	SharedUserSessionID               uint32
+	IsMultiSessionSku                 bool
+	NtSystemRoot                      [260]uint16
+	UserModeGlobalLogger              [16]uint16
+}
+
+// QueryServerSiloUserSharedData queries the user shared data of the server
+// silo. The structure size varies between OS versions, therefore the query
+// is performed with a buffer large enough to fit any known version.
+func QueryServerSiloUserSharedData(hJobObject syscall.Handle) (*SILO_USER_SHARED_DATA, error) {
+	buf := make([]uint64, 512)
+	err := QueryInformationJobObject(hJobObject, JobObjectServerSiloUserSharedData,
+		unsafe.Pointer(&buf[0]),
+		uint32(uintptr(len(buf))*unsafe.Sizeof(buf[0])),
+		nil)
+	if err != nil {
+		return nil, err
+	}
+	data := *(*SILO_USER_SHARED_DATA)(unsafe.Pointer(&buf[0]))
+	return &data, nil
+}
+
 // IsProcessInJob determines whether the process is running in a job object.
 //
 // https://docs.microsoft.com/en-us/windows/desktop/api/jobapi/nf-jobapi-isprocessinjob
@@ -452,6 +837,67 @@ func IsProcessInJob(hProcess, hJobObject syscall.Handle) (bool, error) {
 	return found, nil
 }
 
+// CompareObjectHandles compares two object handles to determine if they refer
+// to the same underlying kernel object. The function is available starting
+// with Windows 10, version 1607; an error is returned if the procedure can not
+// be found.
+//
+// https://docs.microsoft.com/en-us/windows/win32/api/handleapi/nf-handleapi-compareobjecthandles
+func CompareObjectHandles(hFirstObjectHandle, hSecondObjectHandle syscall.Handle) (bool, error) {
+	if err := compareObjectHandles.Find(); err != nil {
+		return false, err
+	}
+	ret, _, _ := compareObjectHandles.Call(
+		uintptr(hFirstObjectHandle),
+		uintptr(hSecondObjectHandle))
+	return ret != 0, nil
+}
+
+// NtQueryObject retrieves various kinds of object information. NTSTATUS
+// returned is translated to a system error code.
+//
+// https://docs.microsoft.com/en-us/windows/win32/api/winternl/nf-winternl-ntqueryobject
+func NtQueryObject(
+	handle syscall.Handle,
+	infoClass ObjectInformationClass,
+	objectInfo unsafe.Pointer,
+	length uint32,
+	retLen unsafe.Pointer) error {
+	status, _, _ := ntQueryObject.Call(
+		uintptr(handle),
+		uintptr(infoClass),
+		uintptr(objectInfo),
+		uintptr(length),
+		uintptr(retLen))
+	if status != 0 {
+		errno, _, _ := rtlNtStatusToDosError.Call(status)
+		return os.NewSyscallError("NtQueryObject", syscall.Errno(errno))
+	}
+	return nil
+}
+
+// QueryObjectName returns the name of the kernel object, including the
+// object directory path, e.g.: \Sessions\1\BaseNamedObjects\name. If the
+// object has no name, an empty string is returned.
+func QueryObjectName(handle syscall.Handle) (string, error) {
+	var retLen uint32
+	b := make([]byte, 512)
+	for {
+		err := NtQueryObject(handle, ObjectNameInformation,
+			unsafe.Pointer(&b[0]),
+			uint32(len(b)),
+			unsafe.Pointer(&retLen))
+		if err == nil {
+			break
+		}
+		if int(retLen) <= len(b) {
+			return "", err
+		}
+		b = make([]byte, retLen)
+	}
+	return (*OBJECT_NAME_INFORMATION)(unsafe.Pointer(&b[0])).Name.String(), nil
+}
+
 // OpenJobObject opens an existing job object.
 //
 // https://docs.microsoft.com/en-us/windows/desktop/api/jobapi2/nf-jobapi2-openjobobjectw
@@ -556,8 +1002,12 @@ func SetInformationJobObject(
 }
 
 // QueryInfo performs QueryInformationJobObject call for the information class specified.
-// A pointer to the appropriate information type must be provided.
+// A pointer to the appropriate information type must be provided. I/O rate
+// control information is queried with QueryIoRateControlInformation.
 func QueryInfo(hJobObject syscall.Handle, infoClass JobObjectInformationClass, v interface{}) error {
+	if info, ok := v.(*JOBOBJECT_IO_RATE_CONTROL_INFORMATION); ok {
+		return QueryIoRateControlInformation(hJobObject, info)
+	}
 	var retLen uint32
 	return QueryInformationJobObject(hJobObject, infoClass,
 		unsafe.Pointer(reflect.ValueOf(v).Pointer()),
@@ -566,8 +1016,12 @@ func QueryInfo(hJobObject syscall.Handle, infoClass JobObjectInformationClass, v
 }
 
 // QueryInfo performs SetInformationJobObject call for the information class specified.
-// A pointer to the appropriate information type must be provided.
+// A pointer to the appropriate information type must be provided. I/O rate
+// control information is set with SetIoRateControlInformation.
 func SetInfo(hJobObject syscall.Handle, infoClass JobObjectInformationClass, v interface{}) error {
+	if info, ok := v.(*JOBOBJECT_IO_RATE_CONTROL_INFORMATION); ok {
+		return SetIoRateControlInformation(hJobObject, info)
+	}
 	return SetInformationJobObject(hJobObject, infoClass,
 		unsafe.Pointer(reflect.ValueOf(v).Pointer()),
 		uint32(reflect.TypeOf(v).Elem().Size()))
@@ -593,8 +1047,17 @@ func MakeSA() *syscall.SecurityAttributes {
 //
 // https://docs.microsoft.com/en-us/windows/desktop/api/winnt/ns-winnt-jobobject_associate_completion_port
 func AssociateCompletionPort(hJobObject, hPort syscall.Handle) error {
+	return AssociateCompletionPortWithKey(hJobObject, hPort, uintptr(hJobObject))
+}
+
+// AssociateCompletionPortWithKey associates a job object with a completion
+// port the same way as AssociateCompletionPort does, but allows to specify
+// the completion key which is used to identify the job object in messages.
+//
+// https://docs.microsoft.com/en-us/windows/desktop/api/winnt/ns-winnt-jobobject_associate_completion_port
+func AssociateCompletionPortWithKey(hJobObject, hPort syscall.Handle, key uintptr) error {
 	jacp := JOBOBJECT_ASSOCIATE_COMPLETION_PORT{
-		CompletionKey:  hJobObject,
+		CompletionKey:  syscall.Handle(key),
 		CompletionPort: hPort,
 	}
 	err := SetInformationJobObject(
@@ -618,20 +1081,142 @@ func AssociateCompletionPort(hJobObject, hPort syscall.Handle) error {
 // does not appear within the specified time, the function times out. For
 // infinite timeout syscall.INFINITE should be used.
 //
+// For job object messages the system passes the process identifier in place
+// of the overlapped structure pointer, therefore the value is never
+// dereferenced, and is returned as pid. The value is not a process identifier
+// (and is undefined) for messages that do not concern a particular process:
+// JOB_OBJECT_MSG_END_OF_JOB_TIME, JOB_OBJECT_MSG_ACTIVE_PROCESS_LIMIT,
+// JOB_OBJECT_MSG_ACTIVE_PROCESS_ZERO, JOB_OBJECT_MSG_JOB_CYCLE_TIME_LIMIT and
+// JOB_OBJECT_MSG_SILO_TERMINATED.
+//
 // https://docs.microsoft.com/en-us/windows/desktop/api/winnt/ns-winnt-jobobject_associate_completion_port
 func GetQueuedCompletionStatus(hPort syscall.Handle, timeout uint32) (mType uint32, pid uintptr, err error) {
 	var (
 		completionKey uint32
-		overlapped    *syscall.Overlapped
+		// The value is an integer rather than a pointer: storing it in a
+		// pointer variable would make the garbage collector treat it as
+		// a memory address.
+		overlapped uintptr
 	)
 	err = syscall.GetQueuedCompletionStatus(
 		hPort,
 		&mType,
 		&completionKey,
-		&overlapped,
+		(**syscall.Overlapped)(unsafe.Pointer(&overlapped)),
 		timeout)
 	if err != nil {
 		return 0, 0, os.NewSyscallError("GetQueuedCompletionStatus", err)
 	}
-	return mType, uintptr(unsafe.Pointer(overlapped)), nil
+	// The process identifier is a DWORD value.
+	return mType, uintptr(uint32(overlapped)), nil
+}
+
+// PostQueuedCompletionStatus posts an I/O completion packet to the completion
+// port. The packet is composed the same way as the system does for job object
+// messages: the message type is passed as the number of bytes transferred,
+// and the process identifier is passed as the overlapped structure pointer.
+//
+// https://docs.microsoft.com/en-us/windows/win32/fileio/postqueuedcompletionstatus
+func PostQueuedCompletionStatus(hPort syscall.Handle, mType uint32, key, pid uintptr) error {
+	ret, _, lastErr := postQueuedCompletionStatus.Call(
+		uintptr(hPort),
+		uintptr(mType),
+		key,
+		pid)
+	if ret == 0 {
+		return os.NewSyscallError("PostQueuedCompletionStatus", lastErr)
+	}
+	return nil
+}
+
+// SetIoRateControlInformation sets I/O limits on a job object.
+//
+// https://docs.microsoft.com/en-us/windows/win32/api/jobapi2/nf-jobapi2-setioratecontrolinformationjobobject
+func SetIoRateControlInformation(hJobObject syscall.Handle, info *JOBOBJECT_IO_RATE_CONTROL_INFORMATION) error {
+	ret, _, lastErr := setIoRateControlInformationJobObject.Call(
+		uintptr(hJobObject),
+		uintptr(unsafe.Pointer(info)))
+	if ret == 0 {
+		return os.NewSyscallError("SetIoRateControlInformationJobObject", lastErr)
+	}
+	return nil
+}
+
+// QueryIoRateControlInformation gets information about the I/O rate control
+// for the volume specified with VolumeName member of info; if the member is
+// nil, the system-wide settings are queried. The first of the returned
+// information blocks is stored to info, and VolumeName is left intact. If no
+// I/O rate control is configured, info members are zeroed.
+//
+// https://docs.microsoft.com/en-us/windows/win32/api/jobapi2/nf-jobapi2-queryioratecontrolinformationjobobject
+func QueryIoRateControlInformation(hJobObject syscall.Handle, info *JOBOBJECT_IO_RATE_CONTROL_INFORMATION) error {
+	var (
+		blocks *JOBOBJECT_IO_RATE_CONTROL_INFORMATION
+		count  uint32
+	)
+	ret, _, lastErr := queryIoRateControlInformationJobObject.Call(
+		uintptr(hJobObject),
+		uintptr(unsafe.Pointer(info.VolumeName)),
+		uintptr(unsafe.Pointer(&blocks)),
+		uintptr(unsafe.Pointer(&count)))
+	if ret == 0 {
+		return os.NewSyscallError("QueryIoRateControlInformationJobObject", lastErr)
+	}
+	volumeName := info.VolumeName
+	if count == 0 || blocks == nil {
+		*info = JOBOBJECT_IO_RATE_CONTROL_INFORMATION{}
+	} else {
+		*info = *blocks
+	}
+	// The name points to the memory allocated by the system.
+	info.VolumeName = volumeName
+	if blocks != nil {
+		_, _, _ = freeMemoryJobObject.Call(uintptr(unsafe.Pointer(blocks)))
+	}
+	return nil
+}
+
+// QueryIoRateControlVolumes returns the names of the volumes I/O rate control
+// is configured for on a job object. An empty name refers to the settings
+// that apply to all volumes.
+//
+// https://docs.microsoft.com/en-us/windows/win32/api/jobapi2/nf-jobapi2-queryioratecontrolinformationjobobject
+func QueryIoRateControlVolumes(hJobObject syscall.Handle) ([]string, error) {
+	var (
+		blocks *JOBOBJECT_IO_RATE_CONTROL_INFORMATION
+		count  uint32
+	)
+	ret, _, lastErr := queryIoRateControlInformationJobObject.Call(
+		uintptr(hJobObject),
+		0,
+		uintptr(unsafe.Pointer(&blocks)),
+		uintptr(unsafe.Pointer(&count)))
+	if ret == 0 {
+		return nil, os.NewSyscallError("QueryIoRateControlInformationJobObject", lastErr)
+	}
+	if blocks == nil {
+		return nil, nil
+	}
+	defer func() {
+		_, _, _ = freeMemoryJobObject.Call(uintptr(unsafe.Pointer(blocks)))
+	}()
+	volumes := make([]string, 0, count)
+	for _, b := range (*[1 << 20]JOBOBJECT_IO_RATE_CONTROL_INFORMATION)(unsafe.Pointer(blocks))[:count:count] {
+		volumes = append(volumes, windows.UTF16PtrToString(b.VolumeName))
+	}
+	return volumes, nil
+}
+
+// GetHandleInformation retrieves properties of an object handle, such as
+// HANDLE_FLAG_INHERIT flag.
+//
+// https://docs.microsoft.com/en-us/windows/win32/api/handleapi/nf-handleapi-gethandleinformation
+func GetHandleInformation(h syscall.Handle) (flags uint32, err error) {
+	ret, _, lastErr := getHandleInformation.Call(
+		uintptr(h),
+		uintptr(unsafe.Pointer(&flags)))
+	if ret == 0 {
+		return 0, os.NewSyscallError("GetHandleInformation", lastErr)
+	}
+	return flags, nil
 }
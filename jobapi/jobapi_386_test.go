@@ -0,0 +1,53 @@
+// +build windows
+
+package jobapi
+
+import (
+	"testing"
+	"unsafe"
+)
+
+// On 386, the ProcessIDList array follows two uint32 counters, therefore it
+// starts at the offset of 8 bytes, which is 2 words; entries are 4 bytes.
+func TestProcessIDListLayout(t *testing.T) {
+	var list JOBOBJECT_BASIC_PROCESS_ID_LIST
+	if x := unsafe.Offsetof(list.ProcessIDList); x != 8 {
+		t.Fatalf("Expected ProcessIDList offset 8, got %d", x)
+	}
+	if processIDListOffset != 2 {
+		t.Fatalf("Expected ProcessIDList offset of 2 words, got %d", processIDListOffset)
+	}
+	if x := unsafe.Sizeof(list.ProcessIDList); x != 4 {
+		t.Fatalf("Expected ProcessIDList entry size 4, got %d", x)
+	}
+}
+
+// The size must match sizeof(JOBOBJECT_EXTENDED_LIMIT_INFORMATION) in C,
+// which requires explicit padding on 386.
+func TestExtendedLimitInformationLayout(t *testing.T) {
+	if x := unsafe.Sizeof(JOBOBJECT_EXTENDED_LIMIT_INFORMATION{}); x != 112 {
+		t.Fatalf("Expected size 112, got %d", x)
+	}
+}
+
+// The size must match sizeof(JOBOBJECT_IO_ATTRIBUTION_INFORMATION) in C.
+func TestIoAttributionInformationLayout(t *testing.T) {
+	var info JOBOBJECT_IO_ATTRIBUTION_INFORMATION
+	if x := unsafe.Offsetof(info.ReadStats); x != 8 {
+		t.Fatalf("Expected ReadStats offset 8, got %d", x)
+	}
+	if x := unsafe.Sizeof(info); x != 72 {
+		t.Fatalf("Expected size 72, got %d", x)
+	}
+}
+
+// The size must match sizeof(JOBOBJECT_IO_RATE_CONTROL_INFORMATION) in C.
+func TestIoRateControlInformationLayout(t *testing.T) {
+	var info JOBOBJECT_IO_RATE_CONTROL_INFORMATION
+	if x := unsafe.Offsetof(info.BaseIoSize); x != 28 {
+		t.Fatalf("Expected BaseIoSize offset 28, got %d", x)
+	}
+	if x := unsafe.Sizeof(info); x != 40 {
+		t.Fatalf("Expected size 40, got %d", x)
+	}
+}
@@ -13,3 +13,37 @@ type JOBOBJECT_EXTENDED_LIMIT_INFORMATION struct {
 	PeakProcessMemoryUsed uintptr
 	PeakJobMemoryUsed     uintptr
 }
+
+// JOBOBJECT_IO_ATTRIBUTION_STATS contains I/O attribution statistics.
+//
+// https://docs.microsoft.com/en-us/windows/win32/api/winnt/ns-winnt-jobobject_io_attribution_information
+type JOBOBJECT_IO_ATTRIBUTION_STATS struct {
+	IoCount                       uintptr
+	TotalNonOverlappedQueueTime   uint64
+	TotalNonOverlappedServiceTime uint64
+	TotalSize                     uint64
+}
+
+// JOBOBJECT_IO_ATTRIBUTION_INFORMATION contains I/O attribution information
+// for a job object.
+//
+// https://docs.microsoft.com/en-us/windows/win32/api/winnt/ns-winnt-jobobject_io_attribution_information
+type JOBOBJECT_IO_ATTRIBUTION_INFORMATION struct {
+	ControlFlags IoAttributionControlFlag
+	ReadStats    JOBOBJECT_IO_ATTRIBUTION_STATS
+	WriteStats   JOBOBJECT_IO_ATTRIBUTION_STATS
+}
+
+// JOBOBJECT_IO_RATE_CONTROL_INFORMATION contains information used to control
+// the I/O rate for a job. The structure is used with JobObjectIoRateControlInformation
+// information class, refer to SetIoRateControlInformation.
+//
+// https://docs.microsoft.com/en-us/windows/win32/api/jobapi2/ns-jobapi2-jobobject_io_rate_control_information
+type JOBOBJECT_IO_RATE_CONTROL_INFORMATION struct {
+	MaxIops         int64
+	MaxBandwidth    int64
+	ReservationIops int64
+	VolumeName      *uint16
+	BaseIoSize      uint32
+	ControlFlags    IoRateControlFlag
+}
@@ -0,0 +1,150 @@
+// +build windows
+
+package jobapi
+
+import (
+	"strings"
+	"testing"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+func TestProcsResolve(t *testing.T) {
+	for _, proc := range []*windows.LazyProc{
+		openJobObject,
+		createJobObject,
+		terminateJobObject,
+		isProcessInJob,
+		assignProcessToJobObject,
+		setInformationJobObject,
+		queryInformationJobObject,
+		postQueuedCompletionStatus,
+		getHandleInformation,
+		ntQueryObject,
+		rtlNtStatusToDosError,
+	} {
+		if err := proc.Find(); err != nil {
+			t.Fatalf("%s: %v", proc.Name, err)
+		}
+	}
+}
+
+func TestLimitFlag_String(t *testing.T) {
+	for _, x := range []struct {
+		flag     LimitFlag
+		expected string
+	}{
+		{0, "0"},
+		{JOB_OBJECT_LIMIT_WORKINGSET, "JOB_OBJECT_LIMIT_WORKINGSET"},
+		{JOB_OBJECT_LIMIT_NET_RATE_CONTROL, "JOB_OBJECT_LIMIT_NET_RATE_CONTROL"},
+		{JOB_OBJECT_LIMIT_CPU_RATE_CONTROL, "JOB_OBJECT_LIMIT_RATE_CONTROL"},
+		{JOB_OBJECT_LIMIT_JOB_MEMORY_HIGH, "JOB_OBJECT_LIMIT_JOB_MEMORY"},
+		{
+			JOB_OBJECT_LIMIT_KILL_ON_JOB_CLOSE | JOB_OBJECT_LIMIT_BREAKAWAY_OK,
+			"JOB_OBJECT_LIMIT_BREAKAWAY_OK|JOB_OBJECT_LIMIT_KILL_ON_JOB_CLOSE",
+		},
+		{
+			JOB_OBJECT_LIMIT_JOB_MEMORY | JOB_OBJECT_LIMIT_JOB_MEMORY_HIGH | 1<<31,
+			"JOB_OBJECT_LIMIT_JOB_MEMORY|0x80000000",
+		},
+	} {
+		if s := x.flag.String(); s != x.expected {
+			t.Errorf("Expected %q, got %q", x.expected, s)
+		}
+	}
+}
+
+func TestPriorityClass_String(t *testing.T) {
+	for _, x := range []struct {
+		class    PriorityClass
+		expected string
+	}{
+		{NORMAL_PRIORITY_CLASS, "NORMAL_PRIORITY_CLASS"},
+		{IDLE_PRIORITY_CLASS, "IDLE_PRIORITY_CLASS"},
+		{HIGH_PRIORITY_CLASS, "HIGH_PRIORITY_CLASS"},
+		{REALTIME_PRIORITY_CLASS, "REALTIME_PRIORITY_CLASS"},
+		{BELOW_NORMAL_PRIORITY_CLASS, "BELOW_NORMAL_PRIORITY_CLASS"},
+		{ABOVE_NORMAL_PRIORITY_CLASS, "ABOVE_NORMAL_PRIORITY_CLASS"},
+		{PROCESS_MODE_BACKGROUND_BEGIN, "PROCESS_MODE_BACKGROUND_BEGIN"},
+		{PROCESS_MODE_BACKGROUND_END, "PROCESS_MODE_BACKGROUND_END"},
+		{0, "unknown(0x00)"},
+		{0x10, "unknown(0x10)"},
+	} {
+		if s := x.class.String(); s != x.expected {
+			t.Errorf("Expected %q, got %q", x.expected, s)
+		}
+	}
+}
+
+func TestJobObjectInformationClass_String(t *testing.T) {
+	for _, x := range []struct {
+		class    JobObjectInformationClass
+		expected string
+	}{
+		{JobObjectBasicAccountingInformation, "JobObjectBasicAccountingInformation"},
+		{JobObjectExtendedLimitInformation, "JobObjectExtendedLimitInformation"},
+		{JobObjectFreezeInformation, "JobObjectFreezeInformation"},
+		{JobObjectNetRateControlInformation, "JobObjectNetRateControlInformation"},
+		{JobObjectThreadImpersonationInformation, "JobObjectThreadImpersonationInformation"},
+		{0, "unknown(0x00)"},
+		{JobObjectThreadImpersonationInformation + 1, "unknown(0x30)"},
+	} {
+		if s := x.class.String(); s != x.expected {
+			t.Errorf("Expected %q, got %q", x.expected, s)
+		}
+	}
+	seen := make(map[string]bool)
+	for c := JobObjectBasicAccountingInformation; c <= JobObjectThreadImpersonationInformation; c++ {
+		s := c.String()
+		if !strings.HasPrefix(s, "JobObject") || seen[s] {
+			t.Errorf("Unexpected name of information class %d: %q", uint32(c), s)
+		}
+		seen[s] = true
+	}
+}
+
+func TestIOCounters(t *testing.T) {
+	for _, x := range []struct {
+		a, b IO_COUNTERS
+		sum  IO_COUNTERS
+		diff IO_COUNTERS
+	}{
+		{
+			a:    IO_COUNTERS{ReadOperationCount: 3, WriteTransferCount: 10},
+			b:    IO_COUNTERS{ReadOperationCount: 1, WriteTransferCount: 4},
+			sum:  IO_COUNTERS{ReadOperationCount: 4, WriteTransferCount: 14},
+			diff: IO_COUNTERS{ReadOperationCount: 2, WriteTransferCount: 6},
+		},
+		{
+			a:    IO_COUNTERS{OtherOperationCount: 1, ReadTransferCount: 5},
+			b:    IO_COUNTERS{OtherOperationCount: 2, ReadTransferCount: 5},
+			sum:  IO_COUNTERS{OtherOperationCount: 3, ReadTransferCount: 10},
+			diff: IO_COUNTERS{},
+		},
+		{
+			a:    IO_COUNTERS{},
+			b:    IO_COUNTERS{WriteOperationCount: 1, OtherTransferCount: 1},
+			sum:  IO_COUNTERS{WriteOperationCount: 1, OtherTransferCount: 1},
+			diff: IO_COUNTERS{},
+		},
+	} {
+		if sum := x.a.Add(x.b); sum != x.sum {
+			t.Fatalf("%+v + %+v: expected %+v, got %+v", x.a, x.b, x.sum, sum)
+		}
+		if diff := x.a.Sub(x.b); diff != x.diff {
+			t.Fatalf("%+v - %+v: expected %+v, got %+v", x.a, x.b, x.diff, diff)
+		}
+	}
+}
+
+// The offsets must match the layout of SILO_USER_SHARED_DATA in C.
+func TestSiloUserSharedDataLayout(t *testing.T) {
+	var data SILO_USER_SHARED_DATA
+	if x := unsafe.Offsetof(data.NtSystemRoot); x != 30 {
+		t.Fatalf("Expected NtSystemRoot offset 30, got %d", x)
+	}
+	if x := unsafe.Offsetof(data.UserModeGlobalLogger); x != 550 {
+		t.Fatalf("Expected UserModeGlobalLogger offset 550, got %d", x)
+	}
+}
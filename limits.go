@@ -3,6 +3,8 @@
 package winjob
 
 import (
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/kolesnikovae/go-winjob/jobapi"
@@ -90,6 +92,13 @@ func WithAffinity(x uintptr) Limit {
 	return LimitAffinity.WithValue(x)
 }
 
+// WithSubsetAffinityMask sets both LimitAffinity with the mask given and
+// LimitSubsetAffinity, which allows threads of the processes associated with
+// the job to use a subset of the affinity mask. Refer to WithAffinity.
+func WithSubsetAffinityMask(mask uintptr) Limit {
+	return LimitAffinity.WithValue(mask).WithSubset()
+}
+
 // WithJobMemoryLimit causes all processes associated with the job to limit the
 // job-wide sum of their committed memory. When a process attempts to commit
 // memory that would exceed the job-wide limit, it fails.
@@ -103,6 +112,14 @@ func WithJobMemoryLimit(x uintptr) Limit {
 	return LimitJobMemory.WithValue(x)
 }
 
+// WithJobMemoryLimitBytes is similar to WithJobMemoryLimit, but accepts the
+// value as a portable byte count. If the value exceeds the platform uintptr
+// range (e.g., 4GB or more on 32-bit systems), the limit fails to apply with
+// ErrInvalidLimit rather than being silently truncated.
+func WithJobMemoryLimitBytes(x uint64) Limit {
+	return LimitJobMemory.WithBytes(x)
+}
+
 // WithJobTimeLimit establishes a user-mode execution time limit for the job.
 //
 // The system adds the current time of the processes associated with the job to
@@ -131,6 +148,13 @@ func WithProcessMemoryLimit(x uintptr) Limit {
 	return LimitProcessMemory.WithValue(x)
 }
 
+// WithProcessMemoryLimitBytes is similar to WithProcessMemoryLimit, but
+// accepts the value as a portable byte count. If the value exceeds the
+// platform uintptr range, the limit fails to apply with ErrInvalidLimit.
+func WithProcessMemoryLimitBytes(x uint64) Limit {
+	return LimitProcessMemory.WithBytes(x)
+}
+
 // WithProcessTimeLimit establishes a user-mode execution time limit for each
 // currently active process and for all future processes associated with the
 // job.
@@ -152,16 +176,26 @@ func WithProcessTimeLimit(x time.Duration) Limit {
 // If you try to associate a process with a job, and this causes the active
 // process count to exceed this limit, the process is terminated and the
 // association fails.
+//
+// The limit is only checked on association: if the job already has more
+// active processes than the limit allows when the limit is set, the
+// processes are not terminated, but no new processes can be associated
+// with the job until the active process count drops below the limit.
 func WithActiveProcessLimit(x uint32) Limit {
 	return LimitActiveProcess.WithValue(x)
 }
 
+// WithMaxProcesses is an alias for WithActiveProcessLimit.
+func WithMaxProcesses(n uint32) Limit {
+	return WithActiveProcessLimit(n)
+}
+
 // WithWorkingSetLimit causes all processes associated with the job to use the
 // same minimum and maximum working set sizes (specified in bytes).
 //
 // If maximum working set size is nonzero, minimum working set cannot be zero,
-// and vice-versa. The actual limit values can be retrieved with
-// MinWorkingSetSize() and MaxWorkingSetSize() methods of LimitWorkingSet.
+// and vice-versa. The actual limit values can be retrieved with LimitValue(),
+// or MinWorkingSetSize() and MaxWorkingSetSize() methods of LimitWorkingSet.
 //
 // If the job is nested, the effective working set size is the smallest
 // working set size in the job chain.
@@ -174,6 +208,13 @@ func WithWorkingSetLimit(min, max uintptr) Limit {
 	return LimitWorkingSet.WithValue(min, max)
 }
 
+// WithWorkingSetLimitBytes is similar to WithWorkingSetLimit, but accepts
+// the values as portable byte counts. If any of the values exceeds the
+// platform uintptr range, the limit fails to apply with ErrInvalidLimit.
+func WithWorkingSetLimitBytes(min, max uint64) Limit {
+	return LimitWorkingSet.WithBytes(min, max)
+}
+
 // WithPriorityClassLimit causes all processes associated with the job to use
 // the same priority class.
 //
@@ -186,6 +227,17 @@ func WithPriorityClassLimit(x jobapi.PriorityClass) Limit {
 	return LimitPriorityClass.WithValue(x)
 }
 
+// WithRealtimePriority is similar to WithPriorityClassLimit with
+// REALTIME_PRIORITY_CLASS value, but ensures the calling process has
+// the SE_INC_BASE_PRIORITY_NAME privilege: the privilege is enabled before
+// the limit is applied. If the process does not hold the privilege, the
+// limit fails to apply with ErrPrivilegeNotHeld.
+func WithRealtimePriority() Limit {
+	l := LimitPriorityClass.WithValue(jobapi.REALTIME_PRIORITY_CLASS)
+	l.privilege = seIncreaseBasePriorityPrivilege
+	return l
+}
+
 // WithSchedulingClassLimit causes all processes in the job to use the same
 // scheduling class.
 //
@@ -201,6 +253,56 @@ func WithSchedulingClassLimit(x uint32) Limit {
 	return LimitSchedulingClass.WithValue(x)
 }
 
+// ErrInvalidLimit is returned when a limit value is invalid. Limits are
+// validated before any changes are applied to the job object.
+var ErrInvalidLimit = errors.New("invalid limit value")
+
+// validator is implemented by limits which values have to be checked before
+// the limit is applied to a job object. The check must not have side effects,
+// since limits are also validated without being applied, e.g. by
+// LimitsFromJSON.
+type validator interface {
+	validate() error
+}
+
+func validateLimits(limits ...Limit) error {
+	for _, limit := range limits {
+		if v, ok := limit.(validator); ok {
+			if err := v.validate(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// preparer is implemented by limits which require the calling process to be
+// prepared before the limit is applied, e.g. a privilege to be enabled.
+// Unlike validate, prepare is only called when the limit is being applied.
+type preparer interface {
+	prepare() error
+}
+
+func prepareLimits(limits ...Limit) error {
+	for _, limit := range limits {
+		if p, ok := limit.(preparer); ok {
+			if err := p.prepare(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// bytesToUintptr converts the byte count to uintptr, ensuring the value
+// fits the platform uintptr range.
+func bytesToUintptr(x uint64) (uintptr, error) {
+	if uint64(uintptr(x)) != x {
+		return 0, fmt.Errorf("%w: %d bytes exceeds uintptr range", ErrInvalidLimit, x)
+	}
+	return uintptr(x), nil
+}
+
 var (
 	LimitBreakawayOK             = basicLimit(jobapi.JOB_OBJECT_LIMIT_BREAKAWAY_OK)
 	LimitDieOnUnhandledException = basicLimit(jobapi.JOB_OBJECT_LIMIT_DIE_ON_UNHANDLED_EXCEPTION)
@@ -241,6 +343,7 @@ func (l basicLimit) Value(job *JobObject) interface{} {
 type affinityLimit struct {
 	basicLimit
 	affinity uintptr
+	subset   bool
 }
 
 func (l affinityLimit) WithValue(x uintptr) affinityLimit {
@@ -248,6 +351,12 @@ func (l affinityLimit) WithValue(x uintptr) affinityLimit {
 	return l
 }
 
+// WithSubset causes the limit to be combined with LimitSubsetAffinity.
+func (l affinityLimit) WithSubset() affinityLimit {
+	l.subset = true
+	return l
+}
+
 func (l affinityLimit) LimitValue(job *JobObject) uintptr {
 	return job.ExtendedLimits.BasicLimitInformation.Affinity
 }
@@ -255,6 +364,23 @@ func (l affinityLimit) LimitValue(job *JobObject) uintptr {
 func (l affinityLimit) set(job *JobObject) {
 	job.ExtendedLimits.BasicLimitInformation.Affinity = l.affinity
 	l.basicLimit.set(job)
+	if l.subset {
+		LimitSubsetAffinity.set(job)
+	}
+}
+
+func (l affinityLimit) reset(job *JobObject) {
+	l.basicLimit.reset(job)
+	if l.subset {
+		LimitSubsetAffinity.reset(job)
+	}
+}
+
+func (l affinityLimit) IsSet(job *JobObject) bool {
+	if l.subset && !LimitSubsetAffinity.IsSet(job) {
+		return false
+	}
+	return l.basicLimit.IsSet(job)
 }
 
 func (l affinityLimit) Value(job *JobObject) interface{} {
@@ -264,13 +390,24 @@ func (l affinityLimit) Value(job *JobObject) interface{} {
 type jobMemoryLimit struct {
 	basicLimit
 	jobMemory uintptr
+	err       error
 }
 
 func (l jobMemoryLimit) WithValue(x uintptr) jobMemoryLimit {
 	l.jobMemory = x
+	l.err = nil
 	return l
 }
 
+func (l jobMemoryLimit) WithBytes(x uint64) jobMemoryLimit {
+	l.jobMemory, l.err = bytesToUintptr(x)
+	return l
+}
+
+func (l jobMemoryLimit) validate() error {
+	return l.err
+}
+
 func (l jobMemoryLimit) LimitValue(job *JobObject) uintptr {
 	return job.ExtendedLimits.JobMemoryLimit
 }
@@ -313,13 +450,24 @@ func (l jobTimeLimit) Value(job *JobObject) interface{} {
 type processMemoryLimit struct {
 	basicLimit
 	processMemory uintptr
+	err           error
 }
 
 func (l processMemoryLimit) WithValue(x uintptr) processMemoryLimit {
 	l.processMemory = x
+	l.err = nil
+	return l
+}
+
+func (l processMemoryLimit) WithBytes(x uint64) processMemoryLimit {
+	l.processMemory, l.err = bytesToUintptr(x)
 	return l
 }
 
+func (l processMemoryLimit) validate() error {
+	return l.err
+}
+
 func (l processMemoryLimit) LimitValue(job *JobObject) uintptr {
 	return job.ExtendedLimits.ProcessMemoryLimit
 }
@@ -383,14 +531,50 @@ type workingSetLimit struct {
 	basicLimit
 	wsMin uintptr
 	wsMax uintptr
+	err   error
+}
+
+// WorkingSet represents minimum and maximum working set sizes, in bytes.
+type WorkingSet struct {
+	Min uintptr
+	Max uintptr
 }
 
 func (l workingSetLimit) WithValue(min, max uintptr) workingSetLimit {
 	l.wsMin = min
 	l.wsMax = max
+	l.err = validateWorkingSet(min, max)
 	return l
 }
 
+func (l workingSetLimit) WithBytes(min, max uint64) workingSetLimit {
+	if l.wsMin, l.err = bytesToUintptr(min); l.err != nil {
+		return l
+	}
+	if l.wsMax, l.err = bytesToUintptr(max); l.err != nil {
+		return l
+	}
+	l.err = validateWorkingSet(l.wsMin, l.wsMax)
+	return l
+}
+
+// validateWorkingSet ensures that either both minimum and maximum working
+// set sizes are zero, or both are nonzero, and the minimum does not exceed
+// the maximum.
+func validateWorkingSet(min, max uintptr) error {
+	switch {
+	case (min == 0) != (max == 0):
+		return fmt.Errorf("%w: minimum and maximum working set sizes must be both zero or nonzero", ErrInvalidLimit)
+	case min > max:
+		return fmt.Errorf("%w: minimum working set size exceeds maximum", ErrInvalidLimit)
+	}
+	return nil
+}
+
+func (l workingSetLimit) validate() error {
+	return l.err
+}
+
 func (l workingSetLimit) MinWorkingSetSize(job *JobObject) uintptr {
 	return job.ExtendedLimits.BasicLimitInformation.MinimumWorkingSetSize
 }
@@ -399,6 +583,17 @@ func (l workingSetLimit) MaxWorkingSetSize(job *JobObject) uintptr {
 	return job.ExtendedLimits.BasicLimitInformation.MaximumWorkingSetSize
 }
 
+func (l workingSetLimit) LimitValue(job *JobObject) WorkingSet {
+	return WorkingSet{
+		Min: l.MinWorkingSetSize(job),
+		Max: l.MaxWorkingSetSize(job),
+	}
+}
+
+func (l workingSetLimit) Value(job *JobObject) interface{} {
+	return l.LimitValue(job)
+}
+
 func (l workingSetLimit) set(job *JobObject) {
 	job.ExtendedLimits.BasicLimitInformation.MinimumWorkingSetSize = l.wsMin
 	job.ExtendedLimits.BasicLimitInformation.MaximumWorkingSetSize = l.wsMax
@@ -408,6 +603,22 @@ func (l workingSetLimit) set(job *JobObject) {
 type priorityClassLimit struct {
 	basicLimit
 	prio jobapi.PriorityClass
+	// privilege to be enabled before the limit is applied, if any.
+	privilege string
+}
+
+func (l priorityClassLimit) validate() error {
+	if l.privilege == "" {
+		return nil
+	}
+	return privilegeHeld(l.privilege)
+}
+
+func (l priorityClassLimit) prepare() error {
+	if l.privilege == "" {
+		return nil
+	}
+	return enablePrivilege(l.privilege)
 }
 
 func (l priorityClassLimit) WithValue(x jobapi.PriorityClass) priorityClassLimit {
@@ -421,9 +632,30 @@ func (l priorityClassLimit) LimitValue(job *JobObject) jobapi.PriorityClass {
 
 func (l priorityClassLimit) set(job *JobObject) {
 	job.ExtendedLimits.BasicLimitInformation.PriorityClass = l.prio
+	job.requestedPriorityClass = l.prio
 	l.basicLimit.set(job)
 }
 
+// EffectivePriorityClass queries the job object for the priority class limit
+// and reports whether the priority class requested with WithPriorityClassLimit
+// is actually in effect. The system may silently ignore the limit, e.g. if the
+// calling process lacks privileges required for REALTIME_PRIORITY_CLASS.
+//
+// If the priority class has not been requested with the JobObject instance
+// (e.g. the job object was opened), the returned bool only reports whether
+// the limit is set.
+func (job *JobObject) EffectivePriorityClass() (jobapi.PriorityClass, bool, error) {
+	err := job.sync(jobapi.QueryInfo, jobapi.JobObjectExtendedLimitInformation)
+	if err != nil {
+		return 0, false, err
+	}
+	if !LimitPriorityClass.IsSet(job) {
+		return 0, false, nil
+	}
+	actual := LimitPriorityClass.LimitValue(job)
+	return actual, job.requestedPriorityClass == 0 || job.requestedPriorityClass == actual, nil
+}
+
 func (l priorityClassLimit) Value(job *JobObject) interface{} {
 	return l.LimitValue(job)
 }
@@ -3,8 +3,7 @@
 package winjob
 
 import (
-	"bytes"
-	"encoding/binary"
+	"fmt"
 
 	"github.com/kolesnikovae/go-winjob/jobapi"
 )
@@ -44,6 +43,24 @@ func WithCPUMinMaxLimit(min, max uint16) Limit {
 	return LimitCPU.WithValue(CPURate{Min: min, Max: max})
 }
 
+// WithCPUHardCapLimitNoNotify is similar to WithCPUHardCapLimit, but the
+// job object does not send notifications when the CPU rate exceeds the limit.
+func WithCPUHardCapLimitNoNotify(v uint32) Limit {
+	return LimitCPU.WithValue(CPURate{HardCap: v}).WithoutNotify()
+}
+
+// WithCPUWeightedLimitNoNotify is similar to WithCPUWeightedLimit, but the
+// job object does not send notifications when the CPU rate exceeds the limit.
+func WithCPUWeightedLimitNoNotify(v uint32) Limit {
+	return LimitCPU.WithValue(CPURate{Weight: v}).WithoutNotify()
+}
+
+// WithCPUMinMaxLimitNoNotify is similar to WithCPUMinMaxLimit, but the job
+// object does not send notifications when the CPU rate exceeds the limit.
+func WithCPUMinMaxLimitNoNotify(min, max uint16) Limit {
+	return LimitCPU.WithValue(CPURate{Min: min, Max: max}).WithoutNotify()
+}
+
 var LimitCPU cpuLimit
 
 type CPURate struct {
@@ -53,10 +70,16 @@ type CPURate struct {
 	HardCap uint32
 }
 
-type cpuLimit CPURate
+type cpuLimit struct {
+	CPURate
+	noNotify bool
+}
 
+// reset clears all the control flags, including ENABLE and NOTIFY ones,
+// as well as the rate value.
 func (l cpuLimit) reset(job *JobObject) {
 	job.CPURateControl.ControlFlags = 0
+	job.CPURateControl.Value = 0
 }
 
 func (l cpuLimit) IsSet(job *JobObject) bool {
@@ -67,8 +90,48 @@ func (l cpuLimit) Value(job *JobObject) interface{} {
 	return l.LimitValue(job)
 }
 
+// validate ensures exactly one of the rate control modes is specified, and
+// the value is within the range accepted by the system.
+func (l cpuLimit) validate() error {
+	modes := 0
+	for _, set := range []bool{l.HardCap > 0, l.Weight > 0, l.Min > 0 || l.Max > 0} {
+		if set {
+			modes++
+		}
+	}
+	switch {
+	case modes != 1:
+		return fmt.Errorf("%w: exactly one of CPU hard cap, weight, "+
+			"or min and max rates must be specified", ErrInvalidLimit)
+	case l.HardCap > 10000:
+		return fmt.Errorf("%w: CPU hard cap %d is out of range 1 to 10000", ErrInvalidLimit, l.HardCap)
+	case l.Weight > 9:
+		return fmt.Errorf("%w: CPU weight %d is out of range 1 to 9", ErrInvalidLimit, l.Weight)
+	case l.Weight == 0 && l.HardCap == 0 && (l.Max == 0 || l.Max > 10000):
+		return fmt.Errorf("%w: CPU max rate %d is out of range 1 to 10000", ErrInvalidLimit, l.Max)
+	case l.Min > l.Max:
+		return fmt.Errorf("%w: CPU min rate exceeds max rate", ErrInvalidLimit)
+	}
+	return nil
+}
+
 func (l cpuLimit) WithValue(x CPURate) cpuLimit {
-	return cpuLimit(x)
+	l.CPURate = x
+	return l
+}
+
+// WithoutNotify disables JOB_OBJECT_CPU_RATE_CONTROL_NOTIFY flag which is
+// set by default, therefore the job object does not send notifications when
+// the CPU rate exceeds the limit.
+func (l cpuLimit) WithoutNotify() cpuLimit {
+	l.noNotify = true
+	return l
+}
+
+// Notifies reports whether the job object sends notifications when the CPU
+// rate exceeds the limit.
+func (l cpuLimit) Notifies(job *JobObject) bool {
+	return job.CPURateControl.ControlFlags&jobapi.JOB_OBJECT_CPU_RATE_CONTROL_NOTIFY > 0
 }
 
 func (l cpuLimit) LimitValue(job *JobObject) CPURate {
@@ -79,10 +142,7 @@ func (l cpuLimit) LimitValue(job *JobObject) CPURate {
 	case job.CPURateControl.ControlFlags&jobapi.JOB_OBJECT_CPU_RATE_CONTROL_WEIGHT_BASED > 0:
 		r.Weight = job.CPURateControl.Value
 	case job.CPURateControl.ControlFlags&jobapi.JOB_OBJECT_CPU_RATE_CONTROL_MIN_MAX_RATE > 0:
-		var b bytes.Buffer
-		_ = binary.Write(&b, binary.LittleEndian, job.CPURateControl.Value)
-		r.Min = binary.LittleEndian.Uint16(b.Bytes()[:2])
-		r.Max = binary.LittleEndian.Uint16(b.Bytes()[2:])
+		r.Min, r.Max = UnpackCPUMinMax(job.CPURateControl.Value)
 	}
 	return r
 }
@@ -98,12 +158,24 @@ func (l cpuLimit) set(job *JobObject) {
 		f = jobapi.JOB_OBJECT_CPU_RATE_CONTROL_WEIGHT_BASED
 	case l.Max > 0:
 		f = jobapi.JOB_OBJECT_CPU_RATE_CONTROL_MIN_MAX_RATE
-		var b bytes.Buffer
-		_ = binary.Write(&b, binary.LittleEndian, l.Min)
-		_ = binary.Write(&b, binary.LittleEndian, l.Max)
-		job.CPURateControl.Value = binary.LittleEndian.Uint32(b.Bytes())
+		job.CPURateControl.Value = PackCPUMinMax(l.Min, l.Max)
 	}
-	job.CPURateControl.ControlFlags = f |
-		jobapi.JOB_OBJECT_CPU_RATE_CONTROL_ENABLE |
-		jobapi.JOB_OBJECT_CPU_RATE_CONTROL_NOTIFY
+	f |= jobapi.JOB_OBJECT_CPU_RATE_CONTROL_ENABLE
+	if !l.noNotify {
+		f |= jobapi.JOB_OBJECT_CPU_RATE_CONTROL_NOTIFY
+	}
+	job.CPURateControl.ControlFlags = f
+}
+
+// PackCPUMinMax packs min and max CPU rates into the value of
+// JOBOBJECT_CPU_RATE_CONTROL_INFORMATION: MinRate occupies the low-order
+// word, and MaxRate occupies the high-order word.
+func PackCPUMinMax(min, max uint16) uint32 {
+	return uint32(min) | uint32(max)<<16
+}
+
+// UnpackCPUMinMax unpacks min and max CPU rates from the value of
+// JOBOBJECT_CPU_RATE_CONTROL_INFORMATION. Refer to PackCPUMinMax.
+func UnpackCPUMinMax(v uint32) (min, max uint16) {
+	return uint16(v), uint16(v >> 16)
 }
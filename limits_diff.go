@@ -0,0 +1,143 @@
+// +build windows
+
+package winjob
+
+import (
+	"reflect"
+
+	"github.com/kolesnikovae/go-winjob/jobapi"
+)
+
+// LimitInfo describes the state of a limit of a job object.
+type LimitInfo struct {
+	Name  string
+	Limit Limit
+	IsSet bool
+	Value interface{}
+}
+
+// LimitDiff describes a limit which state differs between two job objects.
+type LimitDiff struct {
+	Name    string
+	Limit   Limit
+	Desired LimitInfo
+	Actual  LimitInfo
+}
+
+// knownLimits lists all the limits managed by the package that can be
+// examined with the Limits call. The order is stable.
+var knownLimits = []struct {
+	name  string
+	limit Limit
+}{
+	{"BreakawayOK", LimitBreakawayOK},
+	{"DieOnUnhandledException", LimitDieOnUnhandledException},
+	{"KillOnJobClose", LimitKillOnJobClose},
+	{"PreserveJobTime", LimitPreserveJobTime},
+	{"SubsetAffinity", LimitSubsetAffinity},
+	{"SilentBreakawayOK", LimitSilentBreakawayOK},
+
+	{"Affinity", LimitAffinity},
+	{"JobMemory", LimitJobMemory},
+	{"JobTime", LimitJobTime},
+	{"ProcessMemory", LimitProcessMemory},
+	{"ProcessTime", LimitProcessTime},
+	{"ActiveProcess", LimitActiveProcess},
+	{"WorkingSet", LimitWorkingSet},
+	{"PriorityClass", LimitPriorityClass},
+	{"SchedulingClass", LimitSchedulingClass},
+
+	{"Desktop", LimitDesktop},
+	{"DisplaySettings", LimitDisplaySettings},
+	{"ExitWindows", LimitExitWindows},
+	{"GlobalAtoms", LimitGlobalAtoms},
+	{"Handles", LimitHandles},
+	{"SystemParameters", LimitSystemParameters},
+	{"WriteClipboard", LimitWriteClipboard},
+	{"ReadClipboard", LimitReadClipboard},
+
+	{"CPU", LimitCPU},
+	{"OutgoingBandwidth", LimitOutgoingBandwidth},
+	{"DSCPTag", LimitDSCPTag},
+
+	{"EndOfJobTimeNotify", LimitEndOfJobTimeNotify},
+
+	{"NotifyJobMemory", LimitNotifyJobMemory},
+	{"NotifyJobTime", LimitNotifyJobTime},
+	{"NotifyIoReadBytes", LimitNotifyIoReadBytes},
+	{"NotifyIoWriteBytes", LimitNotifyIoWriteBytes},
+}
+
+// Limits returns the state of all the limits managed by the package. Limits
+// should be queried with QueryLimits call beforehand.
+func (job *JobObject) Limits() []LimitInfo {
+	limits := make([]LimitInfo, len(knownLimits))
+	for i, x := range knownLimits {
+		limits[i] = LimitInfo{
+			Name:  x.name,
+			Limit: x.limit,
+			IsSet: x.limit.IsSet(job),
+			Value: x.limit.Value(job),
+		}
+	}
+	return limits
+}
+
+// DiffLimits returns limits which state differs between the desired and
+// actual job objects. A limit is considered different if it is set for
+// one job object only, or if it is set for both of them, but the values
+// do not match. Limits of both job objects should be queried beforehand.
+func DiffLimits(desired, actual *JobObject) []LimitDiff {
+	var diff []LimitDiff
+	actualLimits := actual.Limits()
+	for i, d := range desired.Limits() {
+		a := actualLimits[i]
+		if d.IsSet == a.IsSet && (!d.IsSet || reflect.DeepEqual(d.Value, a.Value)) {
+			continue
+		}
+		diff = append(diff, LimitDiff{
+			Name:    d.Name,
+			Limit:   d.Limit,
+			Desired: d,
+			Actual:  a,
+		})
+	}
+	return diff
+}
+
+// Reconcile converges the job object limits to the desired ones: limits
+// that are not desired are reset, and desired limits that are not set or
+// have different values are applied. Only information classes that differ
+// from the desired state are updated, therefore the call is idempotent.
+func (job *JobObject) Reconcile(desired ...Limit) error {
+	if err := validateLimits(desired...); err != nil {
+		return err
+	}
+	if err := job.QueryLimits(); err != nil {
+		return err
+	}
+	var target JobObject
+	for _, limit := range desired {
+		limit.set(&target)
+	}
+	diff := DiffLimits(&target, job)
+	if len(diff) == 0 {
+		return nil
+	}
+	if err := prepareLimits(desired...); err != nil {
+		return err
+	}
+	classesSet := make(map[jobapi.JobObjectInformationClass]struct{})
+	infoClasses := make([]jobapi.JobObjectInformationClass, 0)
+	for _, d := range diff {
+		infoClass := resolveRequiredInfoClass(d.Limit)
+		if _, ok := classesSet[infoClass]; ok {
+			continue
+		}
+		classesSet[infoClass] = struct{}{}
+		infoClasses = append(infoClasses, infoClass)
+		reflect.ValueOf(job.infoPtr(infoClass)).Elem().
+			Set(reflect.ValueOf(target.infoPtr(infoClass)).Elem())
+	}
+	return job.sync(jobapi.SetInfo, infoClasses...)
+}
@@ -0,0 +1,56 @@
+// +build windows
+
+package winjob
+
+import "github.com/kolesnikovae/go-winjob/jobapi"
+
+// WithEndOfJobTimeNotify causes the system to post a completion packet to the
+// completion port associated with the job when the end-of-job time limit has
+// been exceeded, instead of terminating the processes. After the packet is
+// posted, the system clears the end-of-job time limit and processes in the
+// job can continue their execution.
+//
+// If no completion port is associated with the job when the time limit has
+// been exceeded, the action taken is the same as for
+// WithEndOfJobTimeTerminate.
+func WithEndOfJobTimeNotify() Limit {
+	return LimitEndOfJobTimeNotify
+}
+
+// WithEndOfJobTimeTerminate causes the system to terminate all processes
+// and set the exit status to ERROR_NOT_ENOUGH_QUOTA when the end-of-job time
+// limit has been exceeded. This is the default behavior.
+func WithEndOfJobTimeTerminate() Limit {
+	return LimitEndOfJobTimeTerminate
+}
+
+var (
+	LimitEndOfJobTimeNotify    = endOfJobTimeAction(jobapi.JOB_OBJECT_POST_AT_END_OF_JOB)
+	LimitEndOfJobTimeTerminate = endOfJobTimeAction(jobapi.JOB_OBJECT_TERMINATE_AT_END_OF_JOB)
+)
+
+type endOfJobTimeAction jobapi.EndOfJobTimeAction
+
+func (a endOfJobTimeAction) set(job *JobObject) {
+	job.EndOfJobTime.EndOfJobTimeAction = jobapi.EndOfJobTimeAction(a)
+}
+
+func (a endOfJobTimeAction) reset(job *JobObject) {
+	job.EndOfJobTime.EndOfJobTimeAction = jobapi.JOB_OBJECT_TERMINATE_AT_END_OF_JOB
+}
+
+func (a endOfJobTimeAction) IsSet(job *JobObject) bool {
+	return job.EndOfJobTime.EndOfJobTimeAction == jobapi.EndOfJobTimeAction(a)
+}
+
+func (a endOfJobTimeAction) Value(job *JobObject) interface{} {
+	return job.EndOfJobTime.EndOfJobTimeAction
+}
+
+// SetEndOfJobTimeAction sets the action the system performs when the
+// end-of-job time limit has been exceeded, refer to WithEndOfJobTimeNotify
+// and WithEndOfJobTimeTerminate. JOB_OBJECT_POST_AT_END_OF_JOB action only
+// takes effect if a completion port is associated with the job.
+func (job *JobObject) SetEndOfJobTimeAction(a jobapi.EndOfJobTimeAction) error {
+	return job.SetLimit(endOfJobTimeAction(a))
+}
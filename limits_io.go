@@ -0,0 +1,108 @@
+// +build windows
+
+package winjob
+
+import (
+	"syscall"
+
+	"github.com/kolesnikovae/go-winjob/jobapi"
+)
+
+// WithIORateControl sets the maximum I/O operations per second and the
+// maximum I/O bandwidth in bytes per second for the processes of the job
+// object on the volume specified. If the volume is empty, the limit applies
+// to all volumes. A zero value means no limit.
+//
+// I/O rate control is supported starting with Windows 10; ErrNotSupported is
+// returned on older systems. Note that the limit is set per volume, therefore
+// it is not managed by QueryLimits and ResetLimits calls: use
+// QueryIORateControl to query it, and ResetLimit to reset it. ResetAll resets
+// the limit for all the volumes.
+func WithIORateControl(maxIops, maxBps int64, volume string) Limit {
+	return LimitIORateControl.WithValue(IORate{MaxIops: maxIops, MaxBandwidth: maxBps}, volume)
+}
+
+var LimitIORateControl ioRateControlLimit
+
+// IORate represents I/O rate control limits of a job object.
+type IORate struct {
+	MaxIops      int64
+	MaxBandwidth int64
+}
+
+type ioRateControlLimit struct {
+	rate   IORate
+	volume string
+}
+
+func (l ioRateControlLimit) WithValue(x IORate, volume string) ioRateControlLimit {
+	l.rate = x
+	l.volume = volume
+	return l
+}
+
+func (l ioRateControlLimit) validate() error {
+	if err := requireBuild(buildWindows10, "I/O rate control"); err != nil {
+		return err
+	}
+	_, err := volumeNamePtr(l.volume)
+	return err
+}
+
+func (l ioRateControlLimit) LimitValue(job *JobObject) IORate {
+	return IORate{
+		MaxIops:      job.IORateControl.MaxIops,
+		MaxBandwidth: job.IORateControl.MaxBandwidth,
+	}
+}
+
+func (l ioRateControlLimit) set(job *JobObject) {
+	name, _ := volumeNamePtr(l.volume)
+	job.IORateControl = jobapi.JOBOBJECT_IO_RATE_CONTROL_INFORMATION{
+		MaxIops:      l.rate.MaxIops,
+		MaxBandwidth: l.rate.MaxBandwidth,
+		VolumeName:   name,
+		ControlFlags: jobapi.JOB_OBJECT_IO_RATE_CONTROL_ENABLE,
+	}
+}
+
+func (l ioRateControlLimit) reset(job *JobObject) {
+	name, _ := volumeNamePtr(l.volume)
+	job.IORateControl = jobapi.JOBOBJECT_IO_RATE_CONTROL_INFORMATION{VolumeName: name}
+}
+
+func (l ioRateControlLimit) IsSet(job *JobObject) bool {
+	return job.IORateControl.ControlFlags&jobapi.JOB_OBJECT_IO_RATE_CONTROL_ENABLE > 0
+}
+
+func (l ioRateControlLimit) Value(job *JobObject) interface{} {
+	return l.LimitValue(job)
+}
+
+// volumeNamePtr returns nil for an empty volume name, which refers to all
+// the volumes.
+func volumeNamePtr(volume string) (*uint16, error) {
+	if volume == "" {
+		return nil, nil
+	}
+	return syscall.UTF16PtrFromString(volume)
+}
+
+// QueryIORateControl queries the job object for I/O rate control limits set for
+// the volume specified. If the volume is empty, the limits that apply to all
+// volumes are returned. I/O rate control is supported starting with
+// Windows 10; ErrNotSupported is returned on older systems.
+func (job *JobObject) QueryIORateControl(volume string) (IORate, error) {
+	if err := requireBuild(buildWindows10, "I/O rate control"); err != nil {
+		return IORate{}, err
+	}
+	name, err := volumeNamePtr(volume)
+	if err != nil {
+		return IORate{}, err
+	}
+	job.IORateControl.VolumeName = name
+	if err = job.sync(jobapi.QueryInfo, jobapi.JobObjectIoRateControlInformation); err != nil {
+		return IORate{}, err
+	}
+	return LimitIORateControl.LimitValue(job), nil
+}
@@ -0,0 +1,205 @@
+// +build windows
+
+package winjob
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/kolesnikovae/go-winjob/jobapi"
+)
+
+// LimitsFromJSON parses a JSON document describing job object limits into
+// a list of limits that can be passed to Create or SetLimit call. The
+// document is an object which keys are limit names, as reported by Limits
+// call (LimitInfo.Name), and values are the limit values:
+//
+//  {
+//    "KillOnJobClose": true,
+//    "JobMemory": 104857600,
+//    "JobTime": "10m",
+//    "WorkingSet": {"Min": 1048576, "Max": 10485760},
+//    "CPU": {"HardCap": 5000}
+//  }
+//
+// Limits without a value (e.g. KillOnJobClose) take a boolean: false is
+// equivalent to omitting the limit. Durations are strings accepted by
+// time.ParseDuration, byte counts and other values are numbers, CPU takes
+// CPURate, and WorkingSet takes WorkingSet object.
+//
+// Unknown limit names and invalid values are rejected with ErrInvalidLimit.
+// Returned limits are ordered the same way as Limits call reports them.
+func LimitsFromJSON(r io.Reader) ([]Limit, error) {
+	var doc map[string]json.RawMessage
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(doc))
+	for name := range doc {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, ok := limitDecoders[name]; !ok {
+			return nil, fmt.Errorf("%w: unknown limit %q", ErrInvalidLimit, name)
+		}
+	}
+	var limits []Limit
+	for _, x := range knownLimits {
+		v, ok := doc[x.name]
+		if !ok {
+			continue
+		}
+		limit, err := limitDecoders[x.name](v)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidLimit, x.name, err)
+		}
+		if limit != nil {
+			limits = append(limits, limit)
+		}
+	}
+	if err := validateLimits(limits...); err != nil {
+		return nil, err
+	}
+	return limits, nil
+}
+
+type limitDecoder func(json.RawMessage) (Limit, error)
+
+// limitDecoders maps names of knownLimits to the corresponding decoders.
+// A decoder may return nil Limit, if the limit is to be omitted.
+var limitDecoders = map[string]limitDecoder{
+	"BreakawayOK":             decodeFlag(LimitBreakawayOK),
+	"DieOnUnhandledException": decodeFlag(LimitDieOnUnhandledException),
+	"KillOnJobClose":          decodeFlag(LimitKillOnJobClose),
+	"PreserveJobTime":         decodeFlag(LimitPreserveJobTime),
+	"SubsetAffinity":          decodeFlag(LimitSubsetAffinity),
+	"SilentBreakawayOK":       decodeFlag(LimitSilentBreakawayOK),
+
+	"Affinity": func(b json.RawMessage) (Limit, error) {
+		var x uint64
+		if err := json.Unmarshal(b, &x); err != nil {
+			return nil, err
+		}
+		mask, err := bytesToUintptr(x)
+		if err != nil {
+			return nil, err
+		}
+		return WithAffinity(mask), nil
+	},
+	"JobMemory": func(b json.RawMessage) (Limit, error) {
+		var x uint64
+		err := json.Unmarshal(b, &x)
+		return WithJobMemoryLimitBytes(x), err
+	},
+	"JobTime": func(b json.RawMessage) (Limit, error) {
+		d, err := decodeDuration(b)
+		return WithJobTimeLimit(d), err
+	},
+	"ProcessMemory": func(b json.RawMessage) (Limit, error) {
+		var x uint64
+		err := json.Unmarshal(b, &x)
+		return WithProcessMemoryLimitBytes(x), err
+	},
+	"ProcessTime": func(b json.RawMessage) (Limit, error) {
+		d, err := decodeDuration(b)
+		return WithProcessTimeLimit(d), err
+	},
+	"ActiveProcess": func(b json.RawMessage) (Limit, error) {
+		var x uint32
+		err := json.Unmarshal(b, &x)
+		return WithActiveProcessLimit(x), err
+	},
+	"WorkingSet": func(b json.RawMessage) (Limit, error) {
+		var x struct{ Min, Max uint64 }
+		err := json.Unmarshal(b, &x)
+		return WithWorkingSetLimitBytes(x.Min, x.Max), err
+	},
+	"PriorityClass": func(b json.RawMessage) (Limit, error) {
+		var x jobapi.PriorityClass
+		err := json.Unmarshal(b, &x)
+		return WithPriorityClassLimit(x), err
+	},
+	"SchedulingClass": func(b json.RawMessage) (Limit, error) {
+		var x uint32
+		if err := json.Unmarshal(b, &x); err != nil {
+			return nil, err
+		}
+		if x > 9 {
+			return nil, fmt.Errorf("scheduling class %d is out of range 0 to 9", x)
+		}
+		return WithSchedulingClassLimit(x), nil
+	},
+
+	"Desktop":          decodeFlag(LimitDesktop),
+	"DisplaySettings":  decodeFlag(LimitDisplaySettings),
+	"ExitWindows":      decodeFlag(LimitExitWindows),
+	"GlobalAtoms":      decodeFlag(LimitGlobalAtoms),
+	"Handles":          decodeFlag(LimitHandles),
+	"SystemParameters": decodeFlag(LimitSystemParameters),
+	"WriteClipboard":   decodeFlag(LimitWriteClipboard),
+	"ReadClipboard":    decodeFlag(LimitReadClipboard),
+
+	"CPU": func(b json.RawMessage) (Limit, error) {
+		var x CPURate
+		err := json.Unmarshal(b, &x)
+		return LimitCPU.WithValue(x), err
+	},
+	"OutgoingBandwidth": func(b json.RawMessage) (Limit, error) {
+		var x uint64
+		err := json.Unmarshal(b, &x)
+		return WithOutgoingBandwidthLimit(x), err
+	},
+	"DSCPTag": func(b json.RawMessage) (Limit, error) {
+		var x byte
+		err := json.Unmarshal(b, &x)
+		return WithDSCPTag(x), err
+	},
+
+	"EndOfJobTimeNotify": decodeFlag(LimitEndOfJobTimeNotify),
+
+	"NotifyJobMemory": func(b json.RawMessage) (Limit, error) {
+		var x uint64
+		err := json.Unmarshal(b, &x)
+		return WithNotifyJobMemoryLimit(x), err
+	},
+	"NotifyJobTime": func(b json.RawMessage) (Limit, error) {
+		d, err := decodeDuration(b)
+		return WithNotifyJobTimeLimit(d), err
+	},
+	"NotifyIoReadBytes": func(b json.RawMessage) (Limit, error) {
+		var x uint64
+		err := json.Unmarshal(b, &x)
+		return WithNotifyIoReadBytesLimit(x), err
+	},
+	"NotifyIoWriteBytes": func(b json.RawMessage) (Limit, error) {
+		var x uint64
+		err := json.Unmarshal(b, &x)
+		return WithNotifyIoWriteBytesLimit(x), err
+	},
+}
+
+func decodeFlag(l Limit) limitDecoder {
+	return func(b json.RawMessage) (Limit, error) {
+		var set bool
+		if err := json.Unmarshal(b, &set); err != nil || !set {
+			return nil, err
+		}
+		return l, nil
+	}
+}
+
+func decodeDuration(b json.RawMessage) (time.Duration, error) {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return 0, err
+	}
+	d, err := time.ParseDuration(s)
+	if err == nil && d < 0 {
+		err = fmt.Errorf("negative duration %v", d)
+	}
+	return d, err
+}
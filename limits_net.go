@@ -7,7 +7,10 @@ import (
 )
 
 // WithOutgoingBandwidthLimit sets the maximum bandwidth for outgoing
-// network traffic for the job, in bytes.
+// network traffic for the job, in bytes per second.
+//
+// The limit can be combined with WithDSCPTag: both share the same net rate
+// control information and do not override each other.
 func WithOutgoingBandwidthLimit(b uint64) Limit {
 	return LimitOutgoingBandwidth.WithValue(b)
 }
@@ -16,6 +19,8 @@ func WithOutgoingBandwidthLimit(b uint64) Limit {
 // point (DSCP) field to turn on network quality of service (QoS) for all
 // outgoing network traffic generated by the processes of the job object.
 // The valid range is from 0x00 through 0x3F.
+//
+// The limit can be combined with WithOutgoingBandwidthLimit.
 func WithDSCPTag(t byte) Limit {
 	return LimitDSCPTag.WithValue(t)
 }
@@ -91,3 +96,32 @@ func (l netDSCPTagLimit) IsSet(job *JobObject) bool {
 func (l netDSCPTagLimit) Value(job *JobObject) interface{} {
 	return l.LimitValue(job)
 }
+
+// ClearNetRateControl resets all the net rate control limits of the job
+// object at once: both outgoing bandwidth and DSCP tag are cleared.
+func (job *JobObject) ClearNetRateControl() error {
+	job.NetRateControl = jobapi.JOBOBJECT_NET_RATE_CONTROL_INFORMATION{}
+	return job.sync(jobapi.SetInfo, jobapi.JobObjectNetRateControlInformation)
+}
+
+// NetRate represents net rate control limits of a job object.
+type NetRate struct {
+	MaxBandwidth uint64
+	DSCP         byte
+	Enabled      bool
+}
+
+// NetRate returns net rate control limits of the job object. Limits should
+// be queried with QueryLimits call beforehand. MaxBandwidth and DSCP are only
+// populated if the corresponding limit is set.
+func (job *JobObject) NetRate() NetRate {
+	var r NetRate
+	if LimitOutgoingBandwidth.IsSet(job) {
+		r.MaxBandwidth = job.NetRateControl.MaxBandwidth
+	}
+	if LimitDSCPTag.IsSet(job) {
+		r.DSCP = job.NetRateControl.DscpTag
+	}
+	r.Enabled = job.NetRateControl.ControlFlags&jobapi.JOB_OBJECT_NET_RATE_CONTROL_ENABLE > 0
+	return r
+}
@@ -0,0 +1,173 @@
+// +build windows
+
+package winjob
+
+import (
+	"time"
+
+	"github.com/kolesnikovae/go-winjob/jobapi"
+)
+
+// WithNotifyJobMemoryLimit sets the job-wide committed memory notification
+// limit in bytes. Unlike WithJobMemoryLimit, the limit is not enforced:
+// when it is exceeded, a JOB_OBJECT_MSG_NOTIFICATION_LIMIT message is sent
+// to the completion port associated with the job, and the processes keep
+// running.
+//
+// Unlike most of job object messages, delivery of notification limit
+// messages is guaranteed, refer to Port.
+func WithNotifyJobMemoryLimit(x uint64) Limit {
+	return LimitNotifyJobMemory.WithValue(x)
+}
+
+// WithNotifyJobTimeLimit sets the job-wide user-mode execution time
+// notification limit. When the limit is exceeded, a
+// JOB_OBJECT_MSG_NOTIFICATION_LIMIT message is sent to the completion port
+// associated with the job, and the processes keep running.
+func WithNotifyJobTimeLimit(x time.Duration) Limit {
+	return LimitNotifyJobTime.WithValue(x)
+}
+
+// WithNotifyIoReadBytesLimit sets the job-wide notification limit for the
+// number of bytes read by the job processes. When the limit is exceeded,
+// a JOB_OBJECT_MSG_NOTIFICATION_LIMIT message is sent to the completion port
+// associated with the job.
+func WithNotifyIoReadBytesLimit(x uint64) Limit {
+	return LimitNotifyIoReadBytes.WithValue(x)
+}
+
+// WithNotifyIoWriteBytesLimit sets the job-wide notification limit for the
+// number of bytes written by the job processes. When the limit is exceeded,
+// a JOB_OBJECT_MSG_NOTIFICATION_LIMIT message is sent to the completion port
+// associated with the job.
+func WithNotifyIoWriteBytesLimit(x uint64) Limit {
+	return LimitNotifyIoWriteBytes.WithValue(x)
+}
+
+var (
+	LimitNotifyJobMemory    = notifyJobMemoryLimit{notificationLimit: notificationLimit(jobapi.JOB_OBJECT_LIMIT_JOB_MEMORY)}
+	LimitNotifyJobTime      = notifyJobTimeLimit{notificationLimit: notificationLimit(jobapi.JOB_OBJECT_LIMIT_JOB_TIME)}
+	LimitNotifyIoReadBytes  = notifyIoReadBytesLimit{notificationLimit: notificationLimit(jobapi.JOB_OBJECT_LIMIT_JOB_READ_BYTES)}
+	LimitNotifyIoWriteBytes = notifyIoWriteBytesLimit{notificationLimit: notificationLimit(jobapi.JOB_OBJECT_LIMIT_JOB_WRITE_BYTES)}
+)
+
+type notificationLimit jobapi.LimitFlag
+
+func (l notificationLimit) set(job *JobObject) {
+	job.NotificationLimits.LimitFlags |= jobapi.LimitFlag(l)
+}
+
+func (l notificationLimit) reset(job *JobObject) {
+	job.NotificationLimits.LimitFlags &^= jobapi.LimitFlag(l)
+}
+
+func (l notificationLimit) IsSet(job *JobObject) bool {
+	return job.NotificationLimits.LimitFlags&jobapi.LimitFlag(l) > 0
+}
+
+type notifyJobMemoryLimit struct {
+	notificationLimit
+	jobMemory uint64
+}
+
+func (l notifyJobMemoryLimit) WithValue(x uint64) notifyJobMemoryLimit {
+	l.jobMemory = x
+	return l
+}
+
+func (l notifyJobMemoryLimit) LimitValue(job *JobObject) uint64 {
+	return job.NotificationLimits.JobMemoryLimit
+}
+
+func (l notifyJobMemoryLimit) set(job *JobObject) {
+	job.NotificationLimits.JobMemoryLimit = l.jobMemory
+	l.notificationLimit.set(job)
+}
+
+func (l notifyJobMemoryLimit) Value(job *JobObject) interface{} {
+	return l.LimitValue(job)
+}
+
+type notifyJobTimeLimit struct {
+	notificationLimit
+	jobTime int64
+}
+
+func (l notifyJobTimeLimit) WithValue(x time.Duration) notifyJobTimeLimit {
+	l.jobTime = x.Nanoseconds() / timeFraction
+	return l
+}
+
+func (l notifyJobTimeLimit) LimitValue(job *JobObject) time.Duration {
+	return time.Duration(job.NotificationLimits.PerJobUserTimeLimit * timeFraction)
+}
+
+func (l notifyJobTimeLimit) set(job *JobObject) {
+	job.NotificationLimits.PerJobUserTimeLimit = l.jobTime
+	l.notificationLimit.set(job)
+}
+
+func (l notifyJobTimeLimit) Value(job *JobObject) interface{} {
+	return l.LimitValue(job)
+}
+
+type notifyIoReadBytesLimit struct {
+	notificationLimit
+	bytes uint64
+}
+
+func (l notifyIoReadBytesLimit) WithValue(x uint64) notifyIoReadBytesLimit {
+	l.bytes = x
+	return l
+}
+
+func (l notifyIoReadBytesLimit) LimitValue(job *JobObject) uint64 {
+	return job.NotificationLimits.IoReadBytesLimit
+}
+
+func (l notifyIoReadBytesLimit) set(job *JobObject) {
+	job.NotificationLimits.IoReadBytesLimit = l.bytes
+	l.notificationLimit.set(job)
+}
+
+func (l notifyIoReadBytesLimit) Value(job *JobObject) interface{} {
+	return l.LimitValue(job)
+}
+
+type notifyIoWriteBytesLimit struct {
+	notificationLimit
+	bytes uint64
+}
+
+func (l notifyIoWriteBytesLimit) WithValue(x uint64) notifyIoWriteBytesLimit {
+	l.bytes = x
+	return l
+}
+
+func (l notifyIoWriteBytesLimit) LimitValue(job *JobObject) uint64 {
+	return job.NotificationLimits.IoWriteBytesLimit
+}
+
+func (l notifyIoWriteBytesLimit) set(job *JobObject) {
+	job.NotificationLimits.IoWriteBytesLimit = l.bytes
+	l.notificationLimit.set(job)
+}
+
+func (l notifyIoWriteBytesLimit) Value(job *JobObject) interface{} {
+	return l.LimitValue(job)
+}
+
+// LimitViolation queries the job object for the notification limits that
+// have been exceeded, along with the actual and limit values. The call is
+// supposed to be performed on NotificationNotificationLimit message:
+// ViolationLimitFlags member specifies which limits have been exceeded.
+func (job *JobObject) LimitViolation() (*jobapi.JOBOBJECT_LIMIT_VIOLATION_INFORMATION, error) {
+	if err := job.valid(); err != nil {
+		return nil, err
+	}
+	var info jobapi.JOBOBJECT_LIMIT_VIOLATION_INFORMATION
+	if err := jobapi.QueryInfo(job.Handle, jobapi.JobObjectLimitViolationInformation, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
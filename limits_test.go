@@ -4,7 +4,10 @@ package winjob_test
 
 import (
 	"errors"
+	"os"
+	"os/exec"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -24,7 +27,6 @@ type limitCase struct {
 }
 
 // There are special cases for:
-//   - TestLimits_WorkingSetSizeLimit
 //   - TestLimits_PreserveJobTimeLimit
 //   - TestLimits_AffinityLimit
 //   - TestLimits_CPULimit
@@ -69,7 +71,7 @@ var limitCases = []limitCase{
 	},
 	{
 		winjob.WithWorkingSetLimit(uintptr(8192<<10), uintptr(8<<20)),
-		true, // Refer to TestLimits_WorkingSetSizeLimit
+		winjob.WorkingSet{Min: 8192 << 10, Max: 8 << 20},
 	},
 	{
 		winjob.WithSchedulingClassLimit(4),
@@ -92,6 +94,28 @@ var limitCases = []limitCase{
 		winjob.WithDSCPTag(0x4),
 		byte(0x4),
 	},
+
+	{
+		winjob.WithEndOfJobTimeNotify(),
+		jobapi.JOB_OBJECT_POST_AT_END_OF_JOB,
+	},
+
+	{
+		winjob.WithNotifyJobMemoryLimit(8192 << 10),
+		uint64(8192 << 10),
+	},
+	{
+		winjob.WithNotifyJobTimeLimit(time.Second * 10),
+		time.Second * 10, // May be flaky
+	},
+	{
+		winjob.WithNotifyIoReadBytesLimit(1 << 30),
+		uint64(1 << 30),
+	},
+	{
+		winjob.WithNotifyIoWriteBytesLimit(1 << 30),
+		uint64(1 << 30),
+	},
 }
 
 func (c *limitCase) print(t *testing.T, msg string) {
@@ -208,21 +232,43 @@ func TestLimits_ResetLimits(t *testing.T) {
 	})
 }
 
+func TestLimits_ResetAll(t *testing.T) {
+	runTestWithEmptyJobObject(t, func(job *winjob.JobObject) {
+		requireNoError(t, job.ResetAll())
+		requireNoError(t, job.SetLimit(limitPreset(limitCases)...))
+		ioRateControl := winjob.SupportedFeatures().IORateControl
+		if ioRateControl {
+			requireNoError(t, job.SetLimit(winjob.WithIORateControl(100, 0, "")))
+		}
+		requireNoError(t, job.ResetAll())
+		requireNoError(t, job.QueryLimits())
+		for _, x := range job.Limits() {
+			if x.IsSet {
+				t.Fatalf("%s: %v", x.Name, errLimitNotReset)
+			}
+		}
+		jobHasLimitSubTest(t, job, false)
+		if ioRateControl {
+			rate, err := job.QueryIORateControl("")
+			requireNoError(t, err)
+			if rate != (winjob.IORate{}) || winjob.LimitIORateControl.IsSet(job) {
+				t.Fatalf("IORateControl: %v: %+v", errLimitNotReset, rate)
+			}
+		}
+	})
+}
+
 // LimitWorkingSet modifies two values at once.
 func TestLimits_WorkingSetSizeLimit(t *testing.T) {
 	runTestWithEmptyJobObject(t, func(job *winjob.JobObject) {
-		wsLimit := winjob.LimitWorkingSet.WithValue(1<<20, 8<<20)
-		x := limitCase{limit: wsLimit, expected: true}
+		expected := winjob.WorkingSet{Min: 1 << 20, Max: 8 << 20}
+		x := limitCase{limit: winjob.WithWorkingSetLimit(expected.Min, expected.Max), expected: expected}
 		x.set(t, job)
 		requireNoError(t, job.QueryLimits())
 		x.requireSet(t, job)
-		if wsLimit.MinWorkingSetSize(job) != 1<<20 {
-			t.Fatalf("Incorrect MinWorkingSetSize")
-		}
-		if wsLimit.MaxWorkingSetSize(job) != 8<<20 {
-			t.Fatalf("Incorrect MaxWorkingSetSize")
+		if v := winjob.LimitWorkingSet.LimitValue(job); v != expected {
+			t.Fatalf("Expected %+v, got %+v", expected, v)
 		}
-		requireNoError(t, job.QueryLimits())
 		x.reset(t, job)
 	})
 }
@@ -253,6 +299,184 @@ func TestLimits_AffinityLimit(t *testing.T) {
 	})
 }
 
+func TestLimits_SubsetAffinityMask(t *testing.T) {
+	runTestWithEmptyJobObject(t, func(job *winjob.JobObject) {
+		x := limitCase{winjob.WithSubsetAffinityMask(1), uintptr(1)}
+		requireNoError(t, job.SetLimit(x.limit))
+		x.requireSet(t, job)
+		if !winjob.LimitSubsetAffinity.IsSet(job) {
+			t.Fatal("Subset affinity limit is not set")
+		}
+		x.reset(t, job)
+		if winjob.LimitSubsetAffinity.IsSet(job) {
+			t.Fatal("Subset affinity limit is not reset")
+		}
+	})
+}
+
+func TestLimits_UIRestrictions(t *testing.T) {
+	runTestWithEmptyJobObject(t, func(job *winjob.JobObject) {
+		const bits = jobapi.JOB_OBJECT_UILIMIT_DESKTOP | jobapi.JOB_OBJECT_UILIMIT_HANDLES
+		requireNoError(t, job.SetLimit(winjob.WithUIRestrictions(bits)))
+		v, err := job.UIRestrictionsValue()
+		requireNoError(t, err)
+		if v != bits {
+			t.Fatalf("Expected %#x, got %#x", bits, v)
+		}
+		if !winjob.LimitDesktop.IsSet(job) || !winjob.LimitHandles.IsSet(job) {
+			t.Fatal("UI restrictions are not set")
+		}
+		requireNoError(t, job.ResetLimit(winjob.WithUIRestrictions(bits)))
+		v, err = job.UIRestrictionsValue()
+		requireNoError(t, err)
+		if v != 0 {
+			t.Fatalf("Expected no UI restrictions, got %#x", v)
+		}
+	})
+}
+
+func TestLimits_EffectivePriorityClass(t *testing.T) {
+	runTestWithEmptyJobObject(t, func(job *winjob.JobObject) {
+		_, ok, err := job.EffectivePriorityClass()
+		requireNoError(t, err)
+		if ok {
+			t.Fatal("Priority class limit is not expected to be set")
+		}
+		const x = jobapi.BELOW_NORMAL_PRIORITY_CLASS
+		requireNoError(t, job.SetLimit(winjob.WithPriorityClassLimit(x)))
+		prio, ok, err := job.EffectivePriorityClass()
+		requireNoError(t, err)
+		if !ok || prio != x {
+			t.Fatalf("Expected priority class %#x in effect, got %#x (%v)", x, prio, ok)
+		}
+	})
+}
+
+// The active process limit does not affect processes that are already
+// associated with the job, but prevents new associations.
+func TestLimits_MaxProcessesOrdering(t *testing.T) {
+	runTestWithTestJobObjectWithProcess(t, func(job *winjob.JobObject, _ *os.Process) {
+		requireNoError(t, winjob.StartInJobObject(exec.Command(commandName), job))
+		requireNoError(t, job.SetLimit(winjob.WithMaxProcesses(1)))
+		c, err := job.Counters()
+		requireNoError(t, err)
+		if c.ActiveProcesses != 2 {
+			t.Fatalf("Expected 2 active processes, got %d", c.ActiveProcesses)
+		}
+		if err = winjob.StartInJobObject(exec.Command(commandName), job); err == nil {
+			t.Fatal("Expected process association to fail")
+		}
+	})
+}
+
+func TestLimits_RealtimePriority(t *testing.T) {
+	runTestWithEmptyJobObject(t, func(job *winjob.JobObject) {
+		err := job.SetLimit(winjob.WithRealtimePriority())
+		if errors.Is(err, winjob.ErrPrivilegeNotHeld) {
+			t.Skip(err)
+		}
+		requireNoError(t, err)
+		prio, ok, err := job.EffectivePriorityClass()
+		requireNoError(t, err)
+		if !ok || prio != jobapi.REALTIME_PRIORITY_CLASS {
+			t.Fatalf("Expected realtime priority class in effect, got %#x (%v)", prio, ok)
+		}
+	})
+}
+
+func TestLimits_Reconcile_RealtimePriority(t *testing.T) {
+	runTestWithEmptyJobObject(t, func(job *winjob.JobObject) {
+		err := job.Reconcile(winjob.WithRealtimePriority())
+		if errors.Is(err, winjob.ErrPrivilegeNotHeld) {
+			t.Skip(err)
+		}
+		requireNoError(t, err)
+		requireNoError(t, job.QueryLimits())
+		if prio := winjob.LimitPriorityClass.LimitValue(job); prio != jobapi.REALTIME_PRIORITY_CLASS {
+			t.Fatalf("Expected realtime priority class in effect, got %#x", prio)
+		}
+	})
+}
+
+func TestLimitsFromJSON(t *testing.T) {
+	const doc = `{
+		"KillOnJobClose": true,
+		"BreakawayOK": false,
+		"JobMemory": 8388608,
+		"JobTime": "10m",
+		"WorkingSet": {"Min": 1048576, "Max": 10485760},
+		"CPU": {"HardCap": 5000},
+		"Desktop": true
+	}`
+	limits, err := winjob.LimitsFromJSON(strings.NewReader(doc))
+	requireNoError(t, err)
+	if len(limits) != 6 {
+		t.Fatalf("Expected 6 limits, got %d", len(limits))
+	}
+	runTestWithEmptyJobObject(t, func(job *winjob.JobObject) {
+		requireNoError(t, job.SetLimit(limits...))
+		requireNoError(t, job.QueryLimits())
+		for _, x := range []limitCase{
+			{winjob.LimitKillOnJobClose, true},
+			{winjob.LimitJobMemory, uintptr(8388608)},
+			{winjob.LimitJobTime, time.Minute * 10},
+			{winjob.LimitWorkingSet, winjob.WorkingSet{Min: 1 << 20, Max: 10 << 20}},
+			{winjob.LimitCPU, winjob.CPURate{HardCap: 5000}},
+			{winjob.LimitDesktop, true},
+		} {
+			x.requireSet(t, job)
+		}
+		if winjob.LimitBreakawayOK.IsSet(job) {
+			t.Fatal("BreakawayOK is not expected to be set")
+		}
+	})
+}
+
+func TestLimitsFromJSON_Invalid(t *testing.T) {
+	for _, doc := range []string{
+		`{"Unknown": true}`,
+		`{"KillOnJobClose": 1}`,
+		`{"DSCPTag": 300}`,
+		`{"SchedulingClass": 10}`,
+		`{"JobTime": "-1s"}`,
+		`{"JobTime": 10}`,
+		`{"WorkingSet": {"Min": 2, "Max": 1}}`,
+		`{"CPU": {}}`,
+		`{"CPU": {"HardCap": 20000}}`,
+		`{"CPU": {"Weight": 42}}`,
+		`{"CPU": {"Min": 2000, "Max": 1000}}`,
+		`{"CPU": {"Min": 1000}}`,
+		`{"CPU": {"Max": 20000}}`,
+		`{"CPU": {"HardCap": 5000, "Weight": 5}}`,
+	} {
+		_, err := winjob.LimitsFromJSON(strings.NewReader(doc))
+		if !errors.Is(err, winjob.ErrInvalidLimit) {
+			t.Fatalf("%s: expected %v, got %v", doc, winjob.ErrInvalidLimit, err)
+		}
+	}
+}
+
+func TestLimits_CPULimitNotify(t *testing.T) {
+	runTestWithEmptyJobObject(t, func(job *winjob.JobObject) {
+		for _, x := range []struct {
+			limit  winjob.Limit
+			notify bool
+		}{
+			{winjob.WithCPUHardCapLimit(500), true},
+			{winjob.WithCPUHardCapLimitNoNotify(500), false},
+			{winjob.WithCPUWeightedLimitNoNotify(7), false},
+			{winjob.WithCPUMinMaxLimitNoNotify(500, 1000), false},
+		} {
+			requireNoError(t, job.SetLimit(x.limit))
+			requireNoError(t, job.QueryLimits())
+			if winjob.LimitCPU.Notifies(job) != x.notify {
+				t.Fatalf("%+v: expected notify %v", x.limit, x.notify)
+			}
+			requireNoError(t, job.ResetLimit(x.limit))
+		}
+	})
+}
+
 // Only one CPU limit can be applied to a job object at a time.
 func TestLimits_CPULimit(t *testing.T) {
 	runTestWithEmptyJobObject(t, func(job *winjob.JobObject) {
@@ -265,9 +489,309 @@ func TestLimits_CPULimit(t *testing.T) {
 			x.set(t, job)
 			requireNoError(t, job.QueryLimits())
 			x.requireSet(t, job)
+			if !winjob.LimitCPU.Notifies(job) {
+				t.Fatal("CPU rate control notifications are not enabled")
+			}
 			x.reset(t, job)
 			requireNoError(t, job.QueryLimits())
 			x.requireReset(t, job)
+			if winjob.LimitCPU.Notifies(job) || job.CPURateControl.Value != 0 {
+				t.Fatalf("CPU rate control is not reset: %+v", job.CPURateControl)
+			}
+		}
+	})
+}
+
+func TestLimits_ClearNetRateControl(t *testing.T) {
+	runTestWithEmptyJobObject(t, func(job *winjob.JobObject) {
+		requireNoError(t, job.SetLimit(
+			winjob.WithOutgoingBandwidthLimit(1<<20),
+			winjob.WithDSCPTag(0x4)))
+		requireNoError(t, job.ClearNetRateControl())
+		requireNoError(t, job.QueryLimits())
+		if winjob.LimitOutgoingBandwidth.IsSet(job) || winjob.LimitDSCPTag.IsSet(job) {
+			t.Fatal(errLimitNotReset)
+		}
+	})
+}
+
+// Net rate control limits share the same information class and must not
+// affect each other.
+func TestLimits_NetRateControl(t *testing.T) {
+	runTestWithEmptyJobObject(t, func(job *winjob.JobObject) {
+		bandwidth := limitCase{winjob.WithOutgoingBandwidthLimit(1 << 20), uint64(1 << 20)}
+		dscpTag := limitCase{winjob.WithDSCPTag(0x4), byte(0x4)}
+		bandwidth.set(t, job)
+		dscpTag.set(t, job)
+		requireNoError(t, job.QueryLimits())
+		bandwidth.requireSet(t, job)
+		dscpTag.requireSet(t, job)
+		expected := winjob.NetRate{MaxBandwidth: 1 << 20, DSCP: 0x4, Enabled: true}
+		if r := job.NetRate(); r != expected {
+			t.Fatalf("NetRate missmatch: got %+v, expected %+v", r, expected)
+		}
+		bandwidth.reset(t, job)
+		requireNoError(t, job.QueryLimits())
+		dscpTag.requireSet(t, job)
+		dscpTag.reset(t, job)
+		requireNoError(t, job.QueryLimits())
+		jobHasLimitSubTest(t, job, false)
+	})
+}
+
+// Net rate control limits set with a single call must not override
+// each other's control flags.
+func TestLimits_NetRateControlSingleCall(t *testing.T) {
+	runTestWithEmptyJobObject(t, func(job *winjob.JobObject) {
+		requireNoError(t, job.SetLimit(
+			winjob.WithOutgoingBandwidthLimit(1<<20),
+			winjob.WithDSCPTag(0x4)))
+		requireNoError(t, job.QueryLimits())
+		if job.NetRateControl.MaxBandwidth != 1<<20 || job.NetRateControl.DscpTag != 0x4 {
+			t.Fatalf("Unexpected net rate control: %+v", job.NetRateControl)
+		}
+		expected := winjob.NetRate{MaxBandwidth: 1 << 20, DSCP: 0x4, Enabled: true}
+		if r := job.NetRate(); r != expected {
+			t.Fatalf("NetRate missmatch: got %+v, expected %+v", r, expected)
+		}
+	})
+}
+
+func TestLimits_JobMemoryLimitBytes(t *testing.T) {
+	runTestWithEmptyJobObject(t, func(job *winjob.JobObject) {
+		x := limitCase{winjob.WithJobMemoryLimitBytes(8192 << 10), uintptr(8192 << 10)}
+		x.set(t, job)
+		requireNoError(t, job.QueryLimits())
+		x.requireSet(t, job)
+		x.reset(t, job)
+	})
+}
+
+func requireInvalidLimits(t *testing.T, limits ...winjob.Limit) {
+	runTestWithEmptyJobObject(t, func(job *winjob.JobObject) {
+		for _, limit := range limits {
+			err := job.SetLimit(limit)
+			if !errors.Is(err, winjob.ErrInvalidLimit) {
+				t.Fatalf("Expected %v, got %v", winjob.ErrInvalidLimit, err)
+			}
+		}
+	})
+}
+
+func TestLimits_Validation(t *testing.T) {
+	requireInvalidLimits(t,
+		winjob.WithWorkingSetLimit(0, 8<<20),
+		winjob.WithWorkingSetLimit(8<<20, 0),
+		winjob.WithWorkingSetLimit(8<<20, 1<<20))
+}
+
+func TestLimits_ValidationUintptrRange(t *testing.T) {
+	if uint64(^uintptr(0)) == ^uint64(0) {
+		t.Skip("64-bit uintptr")
+	}
+	requireInvalidLimits(t,
+		winjob.WithJobMemoryLimitBytes(8<<30),
+		winjob.WithProcessMemoryLimitBytes(8<<30),
+		winjob.WithWorkingSetLimitBytes(1<<20, 8<<30))
+}
+
+func TestLimits_DiffLimits(t *testing.T) {
+	runTestWithEmptyJobObject(t, func(desired *winjob.JobObject) {
+		runTestWithEmptyJobObject(t, func(actual *winjob.JobObject) {
+			requireNoError(t, desired.SetLimit(
+				winjob.WithKillOnJobClose(),
+				winjob.WithActiveProcessLimit(2)))
+			requireNoError(t, actual.SetLimit(
+				winjob.WithActiveProcessLimit(2),
+				winjob.WithDesktopLimit()))
+			requireNoError(t, desired.QueryLimits())
+			requireNoError(t, actual.QueryLimits())
+			var names []string
+			for _, d := range winjob.DiffLimits(desired, actual) {
+				names = append(names, d.Name)
+			}
+			expected := []string{"KillOnJobClose", "Desktop"}
+			if !reflect.DeepEqual(names, expected) {
+				t.Fatalf("Expected %v, got %v", expected, names)
+			}
+		})
+	})
+}
+
+func TestLimits_Reconcile(t *testing.T) {
+	runTestWithEmptyJobObject(t, func(job *winjob.JobObject) {
+		requireNoError(t, job.SetLimit(
+			winjob.WithKillOnJobClose(),
+			winjob.WithActiveProcessLimit(2),
+			winjob.WithDesktopLimit()))
+		desired := []winjob.Limit{
+			winjob.WithActiveProcessLimit(3),
+			winjob.WithDSCPTag(0x4),
+		}
+		requireNoError(t, job.Reconcile(desired...))
+		requireNoError(t, job.QueryLimits())
+		for _, x := range []limitCase{
+			{winjob.LimitActiveProcess, uint32(3)},
+			{winjob.LimitDSCPTag, byte(0x4)},
+		} {
+			x.requireSet(t, job)
+		}
+		if winjob.LimitKillOnJobClose.IsSet(job) || winjob.LimitDesktop.IsSet(job) {
+			t.Fatal(errLimitNotReset)
+		}
+		requireNoError(t, job.Reconcile(desired...))
+	})
+}
+
+func TestLimits_CPUMinMaxPacking(t *testing.T) {
+	for _, x := range []struct {
+		min, max uint16
+		packed   uint32
+	}{
+		{0, 0, 0},
+		{500, 1000, 0x03E801F4},
+		{10000, 10000, 0x27102710},
+		{0xFFFF, 0, 0x0000FFFF},
+		{0, 0xFFFF, 0xFFFF0000},
+	} {
+		if v := winjob.PackCPUMinMax(x.min, x.max); v != x.packed {
+			t.Fatalf("PackCPUMinMax(%d, %d): expected %#x, got %#x", x.min, x.max, x.packed, v)
+		}
+		min, max := winjob.UnpackCPUMinMax(x.packed)
+		if min != x.min || max != x.max {
+			t.Fatalf("UnpackCPUMinMax(%#x): expected %d, %d, got %d, %d", x.packed, x.min, x.max, min, max)
+		}
+	}
+}
+
+func TestLimits_SetEndOfJobTimeAction(t *testing.T) {
+	runTestWithEmptyJobObject(t, func(job *winjob.JobObject) {
+		c := make(chan winjob.Notification, 8)
+		s, err := winjob.Notify(c, job)
+		requireNoError(t, err)
+		defer func() {
+			requireNoError(t, s.Close())
+		}()
+		requireNoError(t, job.SetLimit(winjob.WithJobTimeLimit(time.Millisecond*50)))
+		requireNoError(t, job.SetEndOfJobTimeAction(jobapi.JOB_OBJECT_POST_AT_END_OF_JOB))
+		// The process spins in an infinite loop in order to consume
+		// user-mode CPU time.
+		cmd := exec.Command("cmd.exe", "/c", "for /l %i in () do rem")
+		requireNoError(t, winjob.StartInJobObject(cmd, job))
+		defer func() {
+			requireNoError(t, job.Terminate())
+			_ = cmd.Wait()
+		}()
+		timeout := time.After(notificationsTestLimit)
+		for {
+			select {
+			case n := <-c:
+				if n.Type != winjob.NotificationEndOfJobTime {
+					continue
+				}
+				info, err := job.BasicAccounting()
+				requireNoError(t, err)
+				if info.ActiveProcesses == 0 {
+					t.Fatal("Job processes are not expected to be terminated")
+				}
+				return
+			case <-timeout:
+				t.Fatal("No EndOfJobTime notification received")
+			}
+		}
+	})
+}
+
+func TestLimits_NotifyJobMemoryLimit(t *testing.T) {
+	runTestWithEmptyJobObject(t, func(job *winjob.JobObject) {
+		c := make(chan winjob.Notification, 8)
+		s, err := winjob.Notify(c, job)
+		requireNoError(t, err)
+		defer func() {
+			requireNoError(t, s.Close())
+		}()
+		requireNoError(t, job.SetLimit(winjob.WithNotifyJobMemoryLimit(1<<20)))
+		cmd := exec.Command(commandName)
+		requireNoError(t, winjob.StartInJobObject(cmd, job))
+		defer func() {
+			requireNoError(t, job.Terminate())
+			_ = cmd.Wait()
+		}()
+		timeout := time.After(notificationsTestLimit)
+		for {
+			select {
+			case n := <-c:
+				if n.Type != winjob.NotificationNotificationLimit {
+					continue
+				}
+				info, err := job.BasicAccounting()
+				requireNoError(t, err)
+				if info.ActiveProcesses == 0 {
+					t.Fatal("Job processes are not expected to be terminated")
+				}
+				return
+			case <-timeout:
+				t.Fatal("No NotificationLimit notification received")
+			}
+		}
+	})
+}
+
+func TestLimits_LimitViolation(t *testing.T) {
+	runTestWithEmptyJobObject(t, func(job *winjob.JobObject) {
+		c := make(chan winjob.Notification, 8)
+		s, err := winjob.Notify(c, job)
+		requireNoError(t, err)
+		defer func() {
+			requireNoError(t, s.Close())
+		}()
+		requireNoError(t, job.SetLimit(winjob.WithNotifyIoReadBytesLimit(1)))
+		// The process reads the test executable.
+		cmd := exec.Command("cmd.exe", "/c", "type", os.Args[0], ">", "nul")
+		requireNoError(t, winjob.StartInJobObject(cmd, job))
+		defer func() {
+			_ = cmd.Wait()
+		}()
+		timeout := time.After(notificationsTestLimit)
+		for {
+			select {
+			case n := <-c:
+				if n.Type != winjob.NotificationNotificationLimit {
+					continue
+				}
+				v, err := job.LimitViolation()
+				requireNoError(t, err)
+				if v.ViolationLimitFlags&jobapi.JOB_OBJECT_LIMIT_JOB_READ_BYTES == 0 {
+					t.Fatalf("Unexpected violation flags: %v", v.ViolationLimitFlags)
+				}
+				if v.IoReadBytes <= v.IoReadBytesLimit {
+					t.Fatalf("Unexpected violation: %+v", v)
+				}
+				return
+			case <-timeout:
+				t.Fatal("No NotificationLimit notification received")
+			}
+		}
+	})
+}
+
+func TestLimits_IORateControl(t *testing.T) {
+	if !winjob.SupportedFeatures().IORateControl {
+		t.Skip("I/O rate control is not supported")
+	}
+	runTestWithEmptyJobObject(t, func(job *winjob.JobObject) {
+		requireNoError(t, job.SetLimit(winjob.WithIORateControl(100, 0, "")))
+		rate, err := job.QueryIORateControl("")
+		requireNoError(t, err)
+		if rate.MaxIops != 100 {
+			t.Fatalf("Expected MaxIops 100, got %d", rate.MaxIops)
+		}
+		if !winjob.LimitIORateControl.IsSet(job) {
+			t.Fatal(errLimitNotSet)
+		}
+		requireNoError(t, job.ResetLimit(winjob.LimitIORateControl))
+		if winjob.LimitIORateControl.IsSet(job) {
+			t.Fatal(errLimitNotReset)
 		}
 	})
 }
@@ -54,6 +54,22 @@ func WithWriteClipboardLimit() Limit {
 	return LimitWriteClipboard
 }
 
+// WithUIRestrictions sets all the user-interface restrictions specified by
+// the combination of JOB_OBJECT_UILIMIT_* flags at once.
+func WithUIRestrictions(bits jobapi.UIRestrictionsClass) Limit {
+	return uiRestriction(bits)
+}
+
+// UIRestrictionsValue queries the job object for user-interface restrictions
+// and returns the combination of JOB_OBJECT_UILIMIT_* flags set.
+func (job *JobObject) UIRestrictionsValue() (jobapi.UIRestrictionsClass, error) {
+	err := job.sync(jobapi.QueryInfo, jobapi.JobObjectBasicUIRestrictions)
+	if err != nil {
+		return 0, err
+	}
+	return job.UIRestrictions.UIRestrictionsClass, nil
+}
+
 var (
 	LimitDesktop          = uiRestriction(jobapi.JOB_OBJECT_UILIMIT_DESKTOP)
 	LimitDisplaySettings  = uiRestriction(jobapi.JOB_OBJECT_UILIMIT_DISPLAYSETTINGS)
@@ -76,7 +92,8 @@ func (r uiRestriction) reset(job *JobObject) {
 }
 
 func (r uiRestriction) IsSet(job *JobObject) bool {
-	return job.UIRestrictions.UIRestrictionsClass&jobapi.UIRestrictionsClass(r) > 0
+	x := jobapi.UIRestrictionsClass(r)
+	return x != 0 && job.UIRestrictions.UIRestrictionsClass&x == x
 }
 
 func (r uiRestriction) Value(job *JobObject) interface{} {
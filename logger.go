@@ -0,0 +1,33 @@
+// +build windows
+
+package winjob
+
+import "sync"
+
+// Logger is used by the package to report non-fatal internal events, such as
+// retries of failed calls. *log.Logger satisfies the interface.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+var (
+	loggerMu sync.RWMutex
+	logger   Logger
+)
+
+// SetLogger sets the logger the package uses for diagnostic messages.
+// By default, and if the logger is nil, the messages are discarded.
+func SetLogger(l Logger) {
+	loggerMu.Lock()
+	logger = l
+	loggerMu.Unlock()
+}
+
+func logf(format string, v ...interface{}) {
+	loggerMu.RLock()
+	l := logger
+	loggerMu.RUnlock()
+	if l != nil {
+		l.Printf(format, v...)
+	}
+}
@@ -0,0 +1,49 @@
+// +build windows
+
+package winjob
+
+import (
+	"context"
+	"os/exec"
+)
+
+// Measure starts the given command in a new job object with the limits
+// specified, waits for the command to exit, and returns the job object
+// accounting information, including peak memory usage, queried just before
+// the job object is terminated and closed.
+//
+// If the context is done before the command exits, the job object is
+// terminated. Counters are returned even if the command fails, along with
+// the error returned by cmd.Wait.
+func Measure(ctx context.Context, cmd *exec.Cmd, limits ...Limit) (*Counters, error) {
+	job, err := Start(cmd, limits...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = job.Close()
+	}()
+
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		select {
+		case <-ctx.Done():
+			_ = job.Terminate()
+		case <-stop:
+		}
+	}()
+
+	waitErr := cmd.Wait()
+	close(stop)
+	<-stopped
+	var c Counters
+	if err = job.QueryCountersWithMemory(&c); err != nil {
+		return nil, err
+	}
+	if err = job.Terminate(); err != nil {
+		return nil, err
+	}
+	return &c, waitErr
+}
@@ -0,0 +1,24 @@
+// +build windows
+
+package winjob_test
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/kolesnikovae/go-winjob"
+)
+
+func TestMeasure(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*500)
+	defer cancel()
+	c, err := winjob.Measure(ctx, exec.Command(commandName), winjob.WithJobMemoryLimit(64<<20))
+	if _, ok := err.(*exec.ExitError); !ok {
+		t.Fatalf("Expected exit error, got %v", err)
+	}
+	if c.TotalProcesses == 0 || c.PeakJobMemoryUsed == 0 {
+		t.Fatalf("Unexpected counters: %+v", c)
+	}
+}
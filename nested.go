@@ -0,0 +1,105 @@
+// +build windows
+
+package winjob
+
+import (
+	"errors"
+	"syscall"
+
+	"github.com/kolesnikovae/go-winjob/jobapi"
+)
+
+// CreateChild creates a new job object the same way as Create does, and makes
+// it a child of the job: any process assigned to the child job is associated
+// with the parent job first, if it is not yet. This way the child job becomes
+// nested in the parent job once the first process is assigned, and forms a
+// hierarchy of jobs, refer to IsNested. Nested jobs are supported starting
+// with Windows 8 and Windows Server 2012; ErrNotSupported is returned on older
+// systems.
+//
+// Limits of the child job only affect processes of the child job, but the
+// limits of the parent job apply to them as well: the effective limit is the
+// most restrictive limit in the job chain. For example, if the parent job
+// memory is limited to 512MB, processes of a child job with 1GB limit can not
+// commit more than 512MB together with other processes of the parent job.
+//
+// The child job does not own the parent: the parent must not be closed until
+// the child is not used anymore.
+func (job *JobObject) CreateChild(name string, limits ...Limit) (*JobObject, error) {
+	if err := requireBuild(buildWindows8, "nested jobs"); err != nil {
+		return nil, err
+	}
+	if err := job.valid(); err != nil {
+		return nil, err
+	}
+	child, err := Create(name, limits...)
+	if err != nil {
+		return nil, err
+	}
+	child.parent = job
+	return child, nil
+}
+
+// Parent returns the parent job object the job has been created with by
+// CreateChild, or nil otherwise. The OS does not expose parent jobs of a job
+// object, therefore the parent is only known for jobs created by CreateChild.
+func (job *JobObject) Parent() *JobObject {
+	return job.parent
+}
+
+// IsNested reports whether the job is nested in its parent job, i.e. a
+// process has been associated with the job while it belonged to the parent.
+// The OS does not expose parent jobs of a job object, therefore the call only
+// recognizes jobs created by CreateChild: false is returned for other jobs.
+func (job *JobObject) IsNested() (bool, error) {
+	if err := job.valid(); err != nil {
+		return false, err
+	}
+	if job.parent == nil {
+		return false, nil
+	}
+	if job.nested {
+		return true, nil
+	}
+	// Processes may have been assigned bypassing the child job, e.g. by
+	// another JobObject that refers to the same kernel object.
+	pids, err := jobapi.QueryProcessIDList(job.Handle)
+	if err != nil {
+		return false, err
+	}
+	desiredAccess := jobapi.PROCESS_QUERY_LIMITED_INFORMATION
+	for _, pid := range pids {
+		err = withProcessHandle(int(pid), desiredAccess, func(h syscall.Handle) error {
+			job.nested, err = jobapi.IsProcessInJob(h, job.parent.Handle)
+			return err
+		})
+		if err != nil || job.nested {
+			return job.nested, err
+		}
+	}
+	return false, nil
+}
+
+// assignProcess associates the process with the parent job chain, if any,
+// and then with the job itself. If the process can not be associated with a
+// parent job, e.g. because it already belongs to the job chain, the error is
+// ignored: the association with the job itself fails if the jobs can not form
+// a hierarchy.
+func (job *JobObject) assignProcess(hProcess syscall.Handle) error {
+	if job.parent != nil {
+		if err := job.parent.valid(); err != nil {
+			return err
+		}
+		err := job.parent.assignProcess(hProcess)
+		if err != nil && !errors.Is(err, ErrProcessAlreadyInJob) {
+			return err
+		}
+	}
+	if err := assignProcessToJob(job.Handle, hProcess); err != nil {
+		return err
+	}
+	if job.parent != nil {
+		job.nested = true
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+// +build windows
+
+package winjob_test
+
+import (
+	"errors"
+	"os/exec"
+	"testing"
+
+	"github.com/kolesnikovae/go-winjob"
+)
+
+func TestCreateChild(t *testing.T) {
+	runTestWithEmptyJobObject(t, func(parent *winjob.JobObject) {
+		const (
+			parentLimit = 512 << 20
+			childLimit  = 1 << 30
+		)
+		requireNoError(t, parent.SetLimit(winjob.WithJobMemoryLimitBytes(parentLimit)))
+		child, err := parent.CreateChild("", winjob.WithJobMemoryLimitBytes(childLimit))
+		if errors.Is(err, winjob.ErrNotSupported) {
+			t.Skip(err)
+		}
+		requireNoError(t, err)
+		defer func() {
+			requireNoError(t, child.Close())
+		}()
+		if child.Parent() != parent {
+			t.Fatal("Unexpected parent job object")
+		}
+		nested, err := child.IsNested()
+		requireNoError(t, err)
+		if nested {
+			t.Fatal("Job object without processes is not expected to be nested")
+		}
+
+		cmd := exec.Command(commandName)
+		requireNoError(t, cmd.Start())
+		defer func() {
+			requireNoError(t, parent.Terminate())
+			_ = cmd.Wait()
+		}()
+		p := cmd.Process
+		requireNoError(t, child.Assign(p))
+		for _, job := range []*winjob.JobObject{parent, child} {
+			found, err := job.Contains(p)
+			requireNoError(t, err)
+			if !found {
+				t.Fatal("Process is not associated with the job object")
+			}
+		}
+		nested, err = child.IsNested()
+		requireNoError(t, err)
+		if !nested {
+			t.Fatal("Job object is expected to be nested")
+		}
+
+		// The most restrictive limit in the job chain applies.
+		parent.JobInfo = winjob.JobInfo{}
+		limit, err := child.EffectiveMemoryLimit()
+		requireNoError(t, err)
+		if limit != parentLimit {
+			t.Fatalf("Expected effective limit %d, got %d", parentLimit, limit)
+		}
+		if winjob.LimitJobMemory.IsSet(parent) {
+			t.Fatal("Parent job object limits are not expected to be updated")
+		}
+		requireNoError(t, child.QueryLimits())
+		if v := winjob.LimitJobMemory.LimitValue(child); v != childLimit {
+			t.Fatalf("Expected child job limit %d, got %d", childLimit, v)
+		}
+	})
+}
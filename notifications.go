@@ -3,10 +3,15 @@
 package winjob
 
 import (
+	"context"
 	"errors"
-	"fmt"
+	"io"
 	"sync"
+	"sync/atomic"
 	"syscall"
+	"time"
+
+	"golang.org/x/sys/windows"
 
 	"github.com/kolesnikovae/go-winjob/jobapi"
 )
@@ -23,22 +28,53 @@ import (
 // information class, message delivery to a completion port is not guaranteed.
 // Notifications for limits set with JobObjectNotificationLimitInformation are
 // guaranteed to arrive at the completion port.
+//
+// Port satisfies io.Closer interface.
 type Port syscall.Handle
 
+var _ io.Closer = Port(0)
+
 // Subscription is created when a new completion port is being associated
 // with a job object. Refer to Notify function.
 type Subscription struct {
+	// delivered is accessed atomically and must be the first field
+	// to guarantee 64-bit alignment on 32-bit platforms.
+	delivered uint64
+
 	Port
+	// job the port is associated with, refer to closeWithErr.
+	job    *JobObject
 	mu     sync.Mutex
 	err    error
 	closed bool
+	// done is closed once the subscription goroutine exits.
+	done chan struct{}
+	// watchers receive a copy of every notification, refer to watch.
+	watchers map[chan Notification]struct{}
+	stopped  bool
+
+	onNewProcess func(pid int)
 }
 
 // Notification is a CompletionPort message related to a job object.
 type Notification struct {
 	Type NotificationType
-	// If a message does not concern a particular process, the PID will be 0.
+	// PID is the identifier of the process the message concerns. The value
+	// is undefined for messages that do not concern a particular process:
+	// NotificationEndOfJobTime, NotificationActiveProcessLimit,
+	// NotificationActiveProcessZero, NotificationJobCycleLimit, and
+	// NotificationSiloTerminated.
 	PID int
+	// RawType is the original completion port message type. For messages of
+	// NotificationUnknown type it allows to handle the message deterministically.
+	RawType uint32
+}
+
+// Matches reports whether the notification is of the given type and
+// concerns the process specified. If pid is 0, any process matches,
+// including messages that do not concern a particular process.
+func (n Notification) Matches(typ NotificationType, pid int) bool {
+	return n.Type == typ && (pid == 0 || n.PID == pid)
 }
 
 type NotificationType string
@@ -56,6 +92,11 @@ const (
 	NotificationNotificationLimit   = "NotificationLimit"
 	NotificationJobCycleLimit       = "JobCycleLimit"
 	NotificationSiloTerminated      = "SiloTerminated"
+
+	// NotificationUnknown is used for messages which type is not known to the
+	// package, e.g., reserved or introduced in a newer OS version. The original
+	// message type can be found in RawType field of the notification.
+	NotificationUnknown = "Unknown"
 )
 
 var notificationTypes = map[jobapi.CompletionPortMessage]NotificationType{
@@ -73,6 +114,19 @@ var notificationTypes = map[jobapi.CompletionPortMessage]NotificationType{
 	jobapi.JOB_OBJECT_MSG_SILO_TERMINATED:       NotificationSiloTerminated,
 }
 
+// NotificationTypes returns all the notification types that can be emitted,
+// ordered by the underlying job object message identifier. The list does not
+// include NotificationUnknown.
+func NotificationTypes() []NotificationType {
+	types := make([]NotificationType, 0, len(notificationTypes))
+	for m := jobapi.JOB_OBJECT_MSG_MINIMUM; m <= jobapi.JOB_OBJECT_MSG_MAXIMUM; m++ {
+		if t, ok := notificationTypes[m]; ok {
+			types = append(types, t)
+		}
+	}
+	return types
+}
+
 func resolveNotificationType(mType jobapi.CompletionPortMessage) (NotificationType, bool) {
 	t, ok := notificationTypes[mType]
 	return t, ok
@@ -83,46 +137,148 @@ func resolveNotificationType(mType jobapi.CompletionPortMessage) (NotificationTy
 // established, the port handle is closed, and returned Port handle represents
 // the actual handle state. Created Port must be disposed with a Close call.
 func CreatePort(job *JobObject) (p Port, err error) {
+	return CreatePortWithConcurrency(job, 1)
+}
+
+// CreatePortWithConcurrency creates a new job object completion port the same
+// way as CreatePort does, but allows to specify the maximum number of threads
+// that the operating system can allow to concurrently process completion
+// packets for the port. If the value is zero, the system allows as many
+// concurrently running threads as there are processors in the system.
+func CreatePortWithConcurrency(job *JobObject, threads uint32) (p Port, err error) {
+	if err = job.valid(); err != nil {
+		return p, err
+	}
 	// https://docs.microsoft.com/en-us/windows/win32/fileio/createiocompletionport
 	handle, err := syscall.CreateIoCompletionPort(
 		syscall.InvalidHandle, // Ignore ExistingCompletionPort and CompletionKey.
 		0,                     // ExistingCompletionPort
 		0,                     // CompletionKey
-		1,                     // NumberOfConcurrentThreads
+		threads,               // NumberOfConcurrentThreads
 	)
 	if err != nil {
 		return p, err
 	}
-	err = jobapi.AssociateCompletionPort(job.Handle, handle)
-	if err != nil {
+	if err = job.associatePort(handle, 0); err != nil {
 		_ = syscall.CloseHandle(handle)
 	}
 	return Port(handle), err
 }
 
+// ErrPortAlreadyAssociated is returned if a completion port is already
+// associated with the job object.
+var ErrPortAlreadyAssociated = errors.New("completion port is already associated with the job object")
+
+// associatePort associates the completion port with the job object. A job
+// object can only be associated with a single completion port: the system
+// either rejects subsequent associations (Windows 8 and later), or silently
+// replaces the previous one. Associations made with the JobObject are
+// tracked, therefore ErrPortAlreadyAssociated is returned in both cases.
+// Associations made otherwise (e.g. with another handle) are only detected
+// by the system, which fails with ERROR_INVALID_PARAMETER: the error is
+// returned as is, since it is also reported for an invalid port handle.
+func (job *JobObject) associatePort(port syscall.Handle, key uintptr) error {
+	if job.portAssociated {
+		return ErrPortAlreadyAssociated
+	}
+	if err := jobapi.AssociateCompletionPortWithKey(job.Handle, port, key); err != nil {
+		return err
+	}
+	job.portAssociated = true
+	return nil
+}
+
+// CreateAssociated creates a new job object with the limits specified and
+// associates it with the existing completion port using the completion key
+// given. This allows a single caller-owned port to serve multiple job
+// objects. If the job object can not be configured, it is disposed, but
+// the port is left intact: the caller is responsible for closing it.
+func CreateAssociated(name string, port Port, key uintptr, limits ...Limit) (*JobObject, error) {
+	job, err := Create(name, limits...)
+	if err != nil {
+		return nil, err
+	}
+	if err = job.associatePort(syscall.Handle(port), key); err != nil {
+		_ = job.Close()
+		return nil, err
+	}
+	return job, nil
+}
+
 // Close disposes completion port handle.
 func (p Port) Close() error {
 	return syscall.CloseHandle(syscall.Handle(p))
 }
 
+// Handle returns the underlying completion port handle.
+func (p Port) Handle() syscall.Handle {
+	return syscall.Handle(p)
+}
+
+// ErrUnknownNotification is returned by PostNotification if the notification
+// type can not be encoded into a job object message.
+var ErrUnknownNotification = errors.New("unknown notification type")
+
+// PostNotification encodes the notification into a completion packet the
+// same way as the system does for job object messages, and posts it to the
+// port. The message type is taken from RawType, if it is set, otherwise it is
+// resolved from Type. The call is mostly useful for testing: it allows to
+// drive the notification pipeline without actual job object events.
+func (p Port) PostNotification(n Notification) error {
+	mType := n.RawType
+	if mType == 0 {
+		for m, t := range notificationTypes {
+			if t == n.Type {
+				mType = uint32(m)
+				break
+			}
+		}
+	}
+	if mType == 0 {
+		return ErrUnknownNotification
+	}
+	return jobapi.PostQueuedCompletionStatus(syscall.Handle(p), mType, 0, uintptr(n.PID))
+}
+
 // NextMessage blocks until the next completion port message is received,
 // or a Close call, whichever occurs first. If a subscription is closed
 // while the underlying GetQueuedCompletionStatus call was outstanding,
 // a wrapped ErrAbandoned error will be returned.
 func (p Port) NextMessage() (Notification, error) {
-	mType, pid, err := jobapi.GetQueuedCompletionStatus(syscall.Handle(p), syscall.INFINITE)
-	if err != nil {
-		return Notification{}, err
+	m, _, err := p.NextMessageTimeout(-1)
+	return m, err
+}
+
+// NextMessageTimeout blocks until the next completion port message is
+// received, or the timeout expires, whichever occurs first. If no message
+// has been received within the timeout, ok is false and the error is nil.
+// A negative timeout means no timeout.
+func (p Port) NextMessageTimeout(timeout time.Duration) (m Notification, ok bool, err error) {
+	ms := uint32(syscall.INFINITE)
+	if timeout >= 0 && timeout < time.Duration(syscall.INFINITE)*time.Millisecond {
+		ms = uint32(timeout / time.Millisecond)
+	}
+	mType, pid, err := jobapi.GetQueuedCompletionStatus(syscall.Handle(p), ms)
+	switch {
+	case err == nil:
+	case errors.Is(err, windows.WAIT_TIMEOUT), errors.Is(err, windows.ERROR_TIMEOUT):
+		return m, false, nil
+	default:
+		return m, false, err
 	}
+	return newNotification(mType, pid), true, nil
+}
+
+func newNotification(mType uint32, pid uintptr) Notification {
 	typ, ok := resolveNotificationType(jobapi.CompletionPortMessage(mType))
 	if !ok {
-		typ = NotificationType(fmt.Sprintf("%v", mType))
+		typ = NotificationUnknown
 	}
-	m := Notification{
-		Type: typ,
-		PID:  int(pid),
+	return Notification{
+		Type:    typ,
+		PID:     int(pid),
+		RawType: mType,
 	}
-	return m, nil
 }
 
 // Notify causes job to relay notifications to the channel given. The channel
@@ -133,27 +289,149 @@ func Notify(c chan<- Notification, job *JobObject) (*Subscription, error) {
 	if err != nil {
 		return nil, err
 	}
-	s := Subscription{Port: p}
+	s := Subscription{Port: p, job: job, done: make(chan struct{})}
+	job.subscription = &s
 	go s.notify(c)
 	return &s, nil
 }
 
+// NotifyContext causes job to relay notifications to the channel given, the
+// same way as Notify does, but the subscription is also closed when the
+// context is done. In this case Err returns the context error.
+func NotifyContext(ctx context.Context, c chan<- Notification, job *JobObject) (*Subscription, error) {
+	s, err := Notify(c, job)
+	if err != nil {
+		return nil, err
+	}
+	// GetQueuedCompletionStatus call can not observe the context,
+	// therefore the port is closed to interrupt it.
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = s.closeWithErr(ctx.Err())
+		case <-s.done:
+		}
+	}()
+	return s, nil
+}
+
+// Resubscribe associates a new completion port with the job object and
+// relays notifications to the channel given, the same way as Notify does.
+// Any completion port previously associated with the job is disassociated
+// first: this allows to resume notification delivery after the job object
+// has been reopened, e.g. when a service restarts while the job outlives it.
+//
+// Note that messages emitted while no port was associated with the job are
+// lost. Whether the system allows to disassociate a port depends on the
+// operating system version; if the previous association can not be removed,
+// the system fails with ERROR_INVALID_PARAMETER.
+func (job *JobObject) Resubscribe(c chan<- Notification) (*Subscription, error) {
+	if err := job.valid(); err != nil {
+		return nil, err
+	}
+	// The error is ignored deliberately: if the association can not be
+	// removed, the subsequent association fails.
+	_ = jobapi.AssociateCompletionPortWithKey(job.Handle, 0, 0)
+	job.portAssociated = false
+	return Notify(c, job)
+}
+
+// disassociatePort removes the completion port association of the job
+// object, which allows to associate another port. Whether the system allows
+// to disassociate a port depends on the operating system version.
+func (job *JobObject) disassociatePort() error {
+	if err := jobapi.AssociateCompletionPortWithKey(job.Handle, 0, 0); err != nil {
+		return err
+	}
+	job.portAssociated = false
+	return nil
+}
+
+// CreateWithKillTracking creates a new job object with WithKillOnJobClose
+// limit and the limits specified, and associates a completion port with it
+// before any process is assigned. Notifications are relayed to the channel
+// given, the same way as Notify does.
+//
+// Because the port is associated at create time, the subscription outlives
+// the job object handle: after the job object is closed, its processes are
+// terminated, and the subscription observes the teardown notifications
+// (e.g. NotificationExitProcess, NotificationActiveProcessZero) until it is
+// closed explicitly.
+func CreateWithKillTracking(name string, c chan<- Notification, limits ...Limit) (*JobObject, *Subscription, error) {
+	limits = append([]Limit{WithKillOnJobClose()}, limits...)
+	job, err := Create(name, limits...)
+	if err != nil {
+		return nil, nil, err
+	}
+	s, err := Notify(c, job)
+	if err != nil {
+		_ = job.Close()
+		return nil, nil, err
+	}
+	return job, s, nil
+}
+
 // Close interrupts completion port polling, closes port handle and a channel
 // provided to Notify call. The call is thread-safe and supposed to be
-// performed concurrently with notification handling.
+// performed concurrently with notification handling. The port is
+// disassociated from the job object, if the job object handle is still
+// valid, therefore Notify can be called again.
 func (s *Subscription) Close() error {
+	return s.closeWithErr(nil)
+}
+
+// closeWithErr closes the subscription and sets the error to be reported
+// by Err call, if it is not nil.
+func (s *Subscription) closeWithErr(err error) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	if s.closed {
 		return nil
 	}
-	if err := s.Port.Close(); err != nil {
-		return err
+	if closeErr := s.Port.Close(); closeErr != nil {
+		return closeErr
 	}
 	s.closed = true
+	// The job object may have been associated with another port since.
+	if job := s.job; job != nil && job.subscription == s {
+		job.subscription = nil
+		if job.valid() == nil {
+			// If the port can not be disassociated, the association is kept
+			// tracked, and subsequent calls fail with ErrPortAlreadyAssociated.
+			_ = job.disassociatePort()
+		}
+	}
+	if err != nil {
+		s.err = err
+	}
 	return nil
 }
 
+// PortHandle returns the completion port handle of the subscription and
+// reports whether the subscription is still active. The handle must not be
+// closed by the caller: use Close call instead. Note that the handle is only
+// valid while the subscription is active.
+func (s *Subscription) PortHandle() (syscall.Handle, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return syscall.Handle(s.Port), !s.closed && s.err == nil
+}
+
+// OnNewProcess registers a callback that is invoked with the process ID for
+// every NotificationNewProcess message, before the notification is relayed to
+// the channel. The callback is called from the subscription goroutine and
+// must not block, otherwise notification delivery is delayed.
+func (s *Subscription) OnNewProcess(fn func(pid int)) {
+	s.mu.Lock()
+	s.onNewProcess = fn
+	s.mu.Unlock()
+}
+
+// Count returns the number of notifications delivered to the channel.
+func (s *Subscription) Count() uint64 {
+	return atomic.LoadUint64(&s.delivered)
+}
+
 // Err reports an error encountered during completion polling, if any.
 // The call should be done after Notify channel close.
 func (s *Subscription) Err() error {
@@ -163,22 +441,79 @@ func (s *Subscription) Err() error {
 	return err
 }
 
+// watch registers a channel that receives a copy of every notification
+// relayed by the subscription. A notification is dropped if the channel is
+// full. The channel is closed once the subscription goroutine exits. If the
+// subscription is not active anymore, ok is false.
+func (s *Subscription) watch(size int) (c <-chan Notification, unwatch func(), ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped || s.closed {
+		return nil, nil, false
+	}
+	w := make(chan Notification, size)
+	if s.watchers == nil {
+		s.watchers = make(map[chan Notification]struct{})
+	}
+	s.watchers[w] = struct{}{}
+	unwatch = func() {
+		s.mu.Lock()
+		delete(s.watchers, w)
+		s.mu.Unlock()
+	}
+	return w, unwatch, true
+}
+
+func (s *Subscription) broadcast(m Notification) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for w := range s.watchers {
+		select {
+		case w <- m:
+		default:
+		}
+	}
+}
+
+func (s *Subscription) stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for w := range s.watchers {
+		close(w)
+	}
+	s.watchers = nil
+	s.stopped = true
+}
+
 func (s *Subscription) notify(c chan<- Notification) {
+	defer close(s.done)
 	defer close(c)
+	defer s.stop()
 	for {
 		m, err := s.Port.NextMessage()
 		if err != nil {
 			s.handlePortErr(err)
 			return
 		}
+		if m.Type == NotificationNewProcess {
+			s.mu.Lock()
+			fn := s.onNewProcess
+			s.mu.Unlock()
+			if fn != nil {
+				fn(m.PID)
+			}
+		}
+		s.broadcast(m)
 		c <- m
+		atomic.AddUint64(&s.delivered, 1)
 	}
 }
 
 func (s *Subscription) handlePortErr(err error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	if errors.Is(err, jobapi.ErrAbandoned) && s.closed {
+	// The error set on close takes precedence.
+	if s.closed && (errors.Is(err, jobapi.ErrAbandoned) || s.err != nil) {
 		return
 	}
 	s.err = err
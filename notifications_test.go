@@ -3,12 +3,17 @@
 package winjob_test
 
 import (
+	"context"
 	"errors"
+	"io"
 	"os"
+	"os/exec"
 	"syscall"
 	"testing"
 	"time"
 
+	"golang.org/x/sys/windows"
+
 	"github.com/kolesnikovae/go-winjob"
 )
 
@@ -38,6 +43,101 @@ func TestNotifications(t *testing.T) {
 	})
 }
 
+func TestNotifications_PID(t *testing.T) {
+	runTestWithTestJobObjectWithProcess(t, func(job *winjob.JobObject, p *os.Process) {
+		c := make(chan winjob.Notification, 8)
+		s, err := winjob.Notify(c, job)
+		requireNoError(t, err)
+		defer func() {
+			requireNoError(t, s.Close())
+		}()
+		requireNoError(t, p.Kill())
+		timeout := time.After(notificationsTestLimit)
+		for {
+			select {
+			case n := <-c:
+				if n.Type != winjob.NotificationExitProcess && n.Type != winjob.NotificationAbnormalExitProcess {
+					continue
+				}
+				if n.PID != p.Pid {
+					t.Fatalf("Expected PID %d, got %d", p.Pid, n.PID)
+				}
+				return
+			case <-timeout:
+				t.Fatal("No exit notification received")
+			}
+		}
+	})
+}
+
+func TestNotifications_Count(t *testing.T) {
+	runTestWithTestJobObjectWithProcess(t, func(job *winjob.JobObject, p *os.Process) {
+		c := make(chan winjob.Notification, 1)
+		s, err := winjob.Notify(c, job)
+		requireNoError(t, err)
+		defer func() {
+			requireNoError(t, s.Close())
+		}()
+		requireNoError(t, p.Kill())
+		select {
+		case <-c:
+		case <-time.After(notificationsTestLimit):
+			t.Fatal("No notifications received")
+		}
+		// The counter is incremented once the message is sent.
+		deadline := time.Now().Add(notificationsTestLimit)
+		for s.Count() == 0 {
+			if time.Now().After(deadline) {
+				t.Fatal("Delivered notification is not counted")
+			}
+			time.Sleep(time.Millisecond * 10)
+		}
+	})
+}
+
+func TestNotification_Matches(t *testing.T) {
+	n := winjob.Notification{Type: winjob.NotificationExitProcess, PID: 42}
+	for _, x := range []struct {
+		typ      winjob.NotificationType
+		pid      int
+		expected bool
+	}{
+		{winjob.NotificationExitProcess, 42, true},
+		{winjob.NotificationExitProcess, 0, true},
+		{winjob.NotificationExitProcess, 7, false},
+		{winjob.NotificationNewProcess, 42, false},
+		{winjob.NotificationNewProcess, 0, false},
+	} {
+		if n.Matches(x.typ, x.pid) != x.expected {
+			t.Fatalf("%v, %d: expected %v", x.typ, x.pid, x.expected)
+		}
+	}
+	zero := winjob.Notification{Type: winjob.NotificationActiveProcessZero}
+	if zero.Matches(winjob.NotificationActiveProcessZero, 42) {
+		t.Fatal("Notification without PID is not expected to match a process")
+	}
+}
+
+func TestNotificationTypes(t *testing.T) {
+	types := winjob.NotificationTypes()
+	if len(types) != 12 {
+		t.Fatalf("Expected 12 notification types, got %d", len(types))
+	}
+	if types[0] != winjob.NotificationEndOfJobTime {
+		t.Fatalf("Unexpected first notification type: %v", types[0])
+	}
+	if types[len(types)-1] != winjob.NotificationSiloTerminated {
+		t.Fatalf("Unexpected last notification type: %v", types[len(types)-1])
+	}
+	seen := make(map[winjob.NotificationType]bool)
+	for _, x := range types {
+		if x == winjob.NotificationUnknown || seen[x] {
+			t.Fatalf("Unexpected notification type: %v", x)
+		}
+		seen[x] = true
+	}
+}
+
 // The test ensures that the notification channel is closed
 // with close of the subscription created.
 func TestNotifications_Interruption(t *testing.T) {
@@ -58,6 +158,55 @@ func TestNotifications_Interruption(t *testing.T) {
 	})
 }
 
+func TestNotifyContext(t *testing.T) {
+	runTestWithEmptyJobObject(t, func(job *winjob.JobObject) {
+		ctx, cancel := context.WithCancel(context.Background())
+		c := make(chan winjob.Notification, 1)
+		s, err := winjob.NotifyContext(ctx, c, job)
+		requireNoError(t, err)
+		cancel()
+		select {
+		case _, ok := <-c:
+			if ok {
+				t.Fatal("Unexpected notification")
+			}
+		case <-time.After(notificationsTestLimit):
+			t.Fatal("Notification channel is not closed")
+		}
+		if err = s.Err(); err != context.Canceled {
+			t.Fatalf("Expected %v, got %v", context.Canceled, err)
+		}
+		requireNoError(t, s.Close())
+	})
+}
+
+func TestPort_Handle(t *testing.T) {
+	runTestWithEmptyJobObject(t, func(job *winjob.JobObject) {
+		p, err := winjob.CreatePort(job)
+		requireNoError(t, err)
+		var c io.Closer = p
+		if p.Handle() != syscall.Handle(p) {
+			t.Fatal("Port handle mismatch")
+		}
+		requireNoError(t, c.Close())
+	})
+}
+
+func TestNotifications_PortHandle(t *testing.T) {
+	runTestWithEmptyJobObject(t, func(job *winjob.JobObject) {
+		c := make(chan winjob.Notification, 1)
+		s, err := winjob.Notify(c, job)
+		requireNoError(t, err)
+		if _, active := s.PortHandle(); !active {
+			t.Fatal("Subscription is expected to be active")
+		}
+		requireNoError(t, s.Close())
+		if _, active := s.PortHandle(); active {
+			t.Fatal("Subscription is expected to be inactive")
+		}
+	})
+}
+
 // The test ensures that the notification channel is closed on completion
 // port error and the error can be retrieved by Err call.
 func TestNotifications_Error(t *testing.T) {
@@ -80,3 +229,249 @@ func TestNotifications_Error(t *testing.T) {
 		}
 	})
 }
+
+func TestPort_NextMessageTimeout(t *testing.T) {
+	runTestWithEmptyJobObject(t, func(job *winjob.JobObject) {
+		p, err := winjob.CreatePort(job)
+		requireNoError(t, err)
+		defer func() {
+			requireNoError(t, p.Close())
+		}()
+		const timeout = 100 * time.Millisecond
+		start := time.Now()
+		n, ok, err := p.NextMessageTimeout(timeout)
+		requireNoError(t, err)
+		if ok {
+			t.Fatalf("Unexpected notification: %#v", n)
+		}
+		// The system timer resolution is about 15ms.
+		if elapsed := time.Since(start); elapsed < timeout-20*time.Millisecond || elapsed > notificationsTestLimit {
+			t.Fatalf("Unexpected wait duration: %v", elapsed)
+		}
+	})
+}
+
+func TestNotifications_OnNewProcess(t *testing.T) {
+	job, err := newTestJobObject()
+	requireNoError(t, err)
+	defer func() {
+		requireNoError(t, job.Close())
+	}()
+	c := make(chan winjob.Notification, 8)
+	s, err := winjob.Notify(c, job)
+	requireNoError(t, err)
+	defer func() {
+		requireNoError(t, s.Close())
+	}()
+	pids := make(chan int, 1)
+	s.OnNewProcess(func(pid int) {
+		select {
+		case pids <- pid:
+		default:
+		}
+	})
+	cmd := exec.Command(commandName)
+	requireNoError(t, winjob.StartInJobObject(cmd, job))
+	defer func() {
+		requireNoError(t, job.Terminate())
+	}()
+	select {
+	case pid := <-pids:
+		if pid != cmd.Process.Pid {
+			t.Fatalf("Expected PID %d, got %d", cmd.Process.Pid, pid)
+		}
+	case <-time.After(notificationsTestLimit):
+		t.Fatal("No new process notifications received")
+	}
+}
+
+func TestNotifications_Unknown(t *testing.T) {
+	runTestWithEmptyJobObject(t, func(job *winjob.JobObject) {
+		p, err := winjob.CreatePort(job)
+		requireNoError(t, err)
+		defer func() {
+			requireNoError(t, p.Close())
+		}()
+		const reserved = 5
+		requireNoError(t, p.PostNotification(winjob.Notification{RawType: reserved}))
+		n, err := p.NextMessage()
+		requireNoError(t, err)
+		if n.Type != winjob.NotificationUnknown || n.RawType != reserved {
+			t.Fatalf("Unexpected notification: %#v", n)
+		}
+	})
+}
+
+func TestCreatePort_AlreadyAssociated(t *testing.T) {
+	runTestWithEmptyJobObject(t, func(job *winjob.JobObject) {
+		p, err := winjob.CreatePort(job)
+		requireNoError(t, err)
+		defer func() {
+			requireNoError(t, p.Close())
+		}()
+		if _, err = winjob.CreatePort(job); !errors.Is(err, winjob.ErrPortAlreadyAssociated) {
+			t.Fatalf("Expected %v, got %v", winjob.ErrPortAlreadyAssociated, err)
+		}
+		// The association made with another handle is only detected
+		// by the system, starting with Windows 8.
+		opened, err := winjob.Open(job.Name)
+		requireNoError(t, err)
+		defer func() {
+			requireNoError(t, opened.Close())
+		}()
+		c := make(chan winjob.Notification)
+		if _, err = winjob.Notify(c, opened); !errors.Is(err, windows.ERROR_INVALID_PARAMETER) {
+			t.Fatalf("Expected %v, got %v", windows.ERROR_INVALID_PARAMETER, err)
+		}
+		// The failed association is not tracked.
+		if _, err = winjob.Notify(c, opened); !errors.Is(err, windows.ERROR_INVALID_PARAMETER) {
+			t.Fatalf("Expected %v, got %v", windows.ERROR_INVALID_PARAMETER, err)
+		}
+	})
+}
+
+func TestNotify_AfterClose(t *testing.T) {
+	runTestWithEmptyJobObject(t, func(job *winjob.JobObject) {
+		s, err := winjob.Notify(make(chan winjob.Notification), job)
+		requireNoError(t, err)
+		requireNoError(t, s.Close())
+		c := make(chan winjob.Notification, 1)
+		s, err = winjob.Notify(c, job)
+		requireNoError(t, err)
+		defer func() {
+			requireNoError(t, s.Close())
+		}()
+		requireNoError(t, winjob.StartInJobObject(exec.Command(commandName), job))
+		defer func() {
+			requireNoError(t, job.Terminate())
+		}()
+		select {
+		case n := <-c:
+			t.Logf("Notification: %#v", n)
+		case <-time.After(notificationsTestLimit):
+			t.Fatal("No notifications received")
+		}
+	})
+}
+
+func TestResubscribe(t *testing.T) {
+	runTestWithEmptyJobObject(t, func(job *winjob.JobObject) {
+		s, err := winjob.Notify(make(chan winjob.Notification), job)
+		requireNoError(t, err)
+		requireNoError(t, s.Close())
+		opened, err := winjob.Open(job.Name)
+		requireNoError(t, err)
+		defer func() {
+			requireNoError(t, opened.Close())
+		}()
+		c := make(chan winjob.Notification, 1)
+		s, err = opened.Resubscribe(c)
+		if errors.Is(err, windows.ERROR_INVALID_PARAMETER) {
+			t.Skip("Completion port can not be disassociated")
+		}
+		requireNoError(t, err)
+		defer func() {
+			requireNoError(t, s.Close())
+		}()
+		requireNoError(t, winjob.StartInJobObject(exec.Command(commandName), opened))
+		defer func() {
+			requireNoError(t, opened.Terminate())
+		}()
+		select {
+		case n := <-c:
+			t.Logf("Notification: %#v", n)
+		case <-time.After(notificationsTestLimit):
+			t.Fatal("No notifications received")
+		}
+	})
+}
+
+func TestPort_PostNotification(t *testing.T) {
+	runTestWithEmptyJobObject(t, func(job *winjob.JobObject) {
+		p, err := winjob.CreatePort(job)
+		requireNoError(t, err)
+		defer func() {
+			requireNoError(t, p.Close())
+		}()
+		for i, typ := range winjob.NotificationTypes() {
+			requireNoError(t, p.PostNotification(winjob.Notification{Type: typ, PID: i + 1}))
+			n, err := p.NextMessage()
+			requireNoError(t, err)
+			if n.Type != typ || n.PID != i+1 {
+				t.Fatalf("Expected %v for PID %d, got %#v", typ, i+1, n)
+			}
+		}
+		err = p.PostNotification(winjob.Notification{Type: winjob.NotificationUnknown})
+		if err != winjob.ErrUnknownNotification {
+			t.Fatalf("Expected %v, got %v", winjob.ErrUnknownNotification, err)
+		}
+	})
+}
+
+func TestNotifications_PostNotification(t *testing.T) {
+	runTestWithEmptyJobObject(t, func(job *winjob.JobObject) {
+		c := make(chan winjob.Notification, 1)
+		s, err := winjob.Notify(c, job)
+		requireNoError(t, err)
+		defer func() {
+			requireNoError(t, s.Close())
+		}()
+		const pid = 42
+		pids := make(chan int, 1)
+		s.OnNewProcess(func(pid int) { pids <- pid })
+		requireNoError(t, s.Port.PostNotification(winjob.Notification{
+			Type: winjob.NotificationNewProcess,
+			PID:  pid,
+		}))
+		select {
+		case n := <-c:
+			if n.Type != winjob.NotificationNewProcess || n.PID != pid || <-pids != pid {
+				t.Fatalf("Unexpected notification: %#v", n)
+			}
+		case <-time.After(notificationsTestLimit):
+			t.Fatal("No notifications received")
+		}
+	})
+}
+
+func TestCreateAssociated(t *testing.T) {
+	runTestWithEmptyJobObject(t, func(job *winjob.JobObject) {
+		p, err := winjob.CreatePort(job)
+		requireNoError(t, err)
+		defer func() {
+			requireNoError(t, p.Close())
+		}()
+		associated, err := winjob.CreateAssociated("", p, 1, winjob.WithKillOnJobClose())
+		requireNoError(t, err)
+		requireNoError(t, winjob.StartInJobObject(exec.Command(commandName), associated))
+		requireNoError(t, associated.Close())
+		n, ok, err := p.NextMessageTimeout(notificationsTestLimit)
+		requireNoError(t, err)
+		if !ok {
+			t.Fatal("No notifications received")
+		}
+		t.Logf("Notification: %#v", n)
+	})
+}
+
+func TestCreateWithKillTracking(t *testing.T) {
+	c := make(chan winjob.Notification, 8)
+	job, s, err := winjob.CreateWithKillTracking("", c)
+	requireNoError(t, err)
+	defer func() {
+		requireNoError(t, s.Close())
+	}()
+	requireNoError(t, winjob.StartInJobObject(exec.Command(commandName), job))
+	requireNoError(t, job.Close())
+	timeout := time.After(notificationsTestLimit)
+	for {
+		select {
+		case n := <-c:
+			if n.Type == winjob.NotificationActiveProcessZero {
+				return
+			}
+		case <-timeout:
+			t.Fatal("No ActiveProcessZero notification received")
+		}
+	}
+}
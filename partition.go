@@ -0,0 +1,33 @@
+// +build windows
+
+package winjob
+
+import (
+	"syscall"
+
+	"github.com/kolesnikovae/go-winjob/jobapi"
+)
+
+// SetMemoryPartition associates the job object with the memory partition
+// specified by the handle: memory of the processes of the job is allocated
+// from the partition. Memory partitions are supported starting with
+// Windows 10, version 1607; ErrNotSupported is returned on older systems.
+//
+// The package does not manage memory partitions: a partition handle can be
+// obtained with NtCreatePartition or NtOpenPartition native API functions,
+// which are not documented by Microsoft. The handle must be opened with
+// MEMORY_PARTITION_MODIFY_ACCESS access right, and the calling process may
+// need SE_LOCK_MEMORY_NAME privilege enabled.
+//
+// Note that JobObjectMemoryPartitionInformation information class is not
+// documented by Microsoft.
+func (job *JobObject) SetMemoryPartition(h syscall.Handle) error {
+	if err := job.valid(); err != nil {
+		return err
+	}
+	if err := requireBuild(buildWindows10v1607, "memory partition"); err != nil {
+		return err
+	}
+	info := jobapi.JOBOBJECT_MEMORY_PARTITION_INFORMATION{Partition: h}
+	return jobapi.SetInfo(job.Handle, jobapi.JobObjectMemoryPartitionInformation, &info)
+}
@@ -0,0 +1,104 @@
+// +build windows
+
+package winjob
+
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// ErrPrivilegeNotHeld is returned if a limit requires a privilege that the
+// calling process does not hold.
+var ErrPrivilegeNotHeld = errors.New("privilege is not held")
+
+const seIncreaseBasePriorityPrivilege = "SeIncreaseBasePriorityPrivilege"
+
+// enablePrivilege enables the privilege in the calling process token. If the
+// token does not hold the privilege, ErrPrivilegeNotHeld is returned.
+func enablePrivilege(name string) error {
+	return withPrivilege(name, windows.TOKEN_ADJUST_PRIVILEGES|windows.TOKEN_QUERY,
+		func(token windows.Token, luid windows.LUID) error {
+			p := windows.Tokenprivileges{PrivilegeCount: 1}
+			p.Privileges[0] = windows.LUIDAndAttributes{
+				Luid:       luid,
+				Attributes: windows.SE_PRIVILEGE_ENABLED,
+			}
+			if err := windows.AdjustTokenPrivileges(token, false, &p, 0, nil, nil); err != nil {
+				return fmt.Errorf("AdjustTokenPrivileges: %w", err)
+			}
+			// AdjustTokenPrivileges succeeds even if the privilege is not
+			// held, therefore the token privileges are to be checked.
+			attrs, found, err := tokenPrivilege(token, luid)
+			if err != nil {
+				return err
+			}
+			if !found || attrs&windows.SE_PRIVILEGE_ENABLED == 0 {
+				return fmt.Errorf("%w: %s", ErrPrivilegeNotHeld, name)
+			}
+			return nil
+		})
+}
+
+// privilegeHeld checks whether the calling process token holds the
+// privilege, which is not necessarily enabled. The token is not modified.
+// If the privilege is not held, ErrPrivilegeNotHeld is returned.
+func privilegeHeld(name string) error {
+	return withPrivilege(name, windows.TOKEN_QUERY,
+		func(token windows.Token, luid windows.LUID) error {
+			_, found, err := tokenPrivilege(token, luid)
+			if err != nil {
+				return err
+			}
+			if !found {
+				return fmt.Errorf("%w: %s", ErrPrivilegeNotHeld, name)
+			}
+			return nil
+		})
+}
+
+// withPrivilege opens the calling process token with the access given, looks
+// up the privilege LUID by its name, and calls fn.
+func withPrivilege(name string, access uint32, fn func(windows.Token, windows.LUID) error) error {
+	var token windows.Token
+	err := windows.OpenProcessToken(windows.CurrentProcess(), access, &token)
+	if err != nil {
+		return fmt.Errorf("OpenProcessToken: %w", err)
+	}
+	defer func() {
+		_ = token.Close()
+	}()
+
+	n, err := windows.UTF16PtrFromString(name)
+	if err != nil {
+		return err
+	}
+	var luid windows.LUID
+	if err = windows.LookupPrivilegeValue(nil, n, &luid); err != nil {
+		return fmt.Errorf("LookupPrivilegeValue: %w", err)
+	}
+	return fn(token, luid)
+}
+
+// tokenPrivilege returns the attributes of the privilege, and reports whether
+// the token holds it.
+func tokenPrivilege(token windows.Token, luid windows.LUID) (uint32, bool, error) {
+	var size uint32
+	_ = windows.GetTokenInformation(token, windows.TokenPrivileges, nil, 0, &size)
+	if size == 0 {
+		return 0, false, nil
+	}
+	b := make([]byte, size)
+	err := windows.GetTokenInformation(token, windows.TokenPrivileges, &b[0], size, &size)
+	if err != nil {
+		return 0, false, fmt.Errorf("GetTokenInformation: %w", err)
+	}
+	for _, x := range (*windows.Tokenprivileges)(unsafe.Pointer(&b[0])).AllPrivileges() {
+		if x.Luid == luid {
+			return x.Attributes, true, nil
+		}
+	}
+	return 0, false, nil
+}
@@ -3,11 +3,14 @@
 package winjob
 
 import (
+	"errors"
 	"fmt"
 	"os/exec"
 	"unsafe"
 
 	"golang.org/x/sys/windows"
+
+	"github.com/kolesnikovae/go-winjob/jobapi"
 )
 
 // Start creates a job object with the limits specified and starts the given
@@ -25,14 +28,54 @@ func Start(cmd *exec.Cmd, limits ...Limit) (*JobObject, error) {
 	return job, nil
 }
 
+// StartKillOnClose creates a job object with WithKillOnJobClose and
+// WithBreakawayOK limits, along with the extra limits specified, and starts
+// the given command within the job.
+//
+// All the job object processes are terminated when the last handle to the
+// job object is closed. The returned JobObject holds the handle, therefore
+// the caller must not close it until the process tree should be terminated:
+//
+//  job, err := winjob.StartKillOnClose(cmd)
+//  if err != nil {
+//    // ...
+//  }
+//  // The process tree is terminated on return.
+//  defer job.Close()
+//  if err := cmd.Wait(); err != nil {
+//    // ...
+//  }
+//
+// Note that the system closes all handles of a process when it exits, thus
+// the process tree is also terminated if the calling process exits.
+func StartKillOnClose(cmd *exec.Cmd, extra ...Limit) (*JobObject, error) {
+	limits := append([]Limit{WithKillOnJobClose(), WithBreakawayOK()}, extra...)
+	return Start(cmd, limits...)
+}
+
+// RunContained starts the given command in a new job object with the limits
+// specified, waits for it to complete, and closes the job object. The job
+// object is created with WithKillOnJobClose limit, therefore any processes
+// left running by the command are terminated once the command exits. The job
+// object is closed even if the command fails; in this case the error returned
+// by cmd.Wait takes precedence over the job object close error.
+func RunContained(cmd *exec.Cmd, limits ...Limit) error {
+	job, err := Start(cmd, append([]Limit{WithKillOnJobClose()}, limits...)...)
+	if err != nil {
+		return err
+	}
+	err = cmd.Wait()
+	if closeErr := job.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
 // StartInJobObject starts the given command within the job objects specified.
 // The process is created with suspended threads which are resumed when the
 // process is added to the job.
 func StartInJobObject(cmd *exec.Cmd, job *JobObject) error {
-	if cmd.SysProcAttr == nil {
-		cmd.SysProcAttr = new(windows.SysProcAttr)
-	}
-	cmd.SysProcAttr.CreationFlags |= windows.CREATE_SUSPENDED
+	setSuspended(cmd)
 	if err := cmd.Start(); err != nil {
 		return err
 	}
@@ -42,6 +85,68 @@ func StartInJobObject(cmd *exec.Cmd, job *JobObject) error {
 	return Resume(cmd)
 }
 
+// ErrBreakawayNotAllowed is returned by StartBreakaway if the job object
+// does not allow breakaway.
+var ErrBreakawayNotAllowed = errors.New("job object does not allow breakaway")
+
+// StartBreakaway starts the given command with CREATE_BREAKAWAY_FROM_JOB
+// flag, so that the process is not associated with the job object the
+// calling process belongs to. This only makes sense if the calling process
+// is associated with the job, e.g. with AssignCurrentProcess.
+//
+// The job object must have WithBreakawayOK limit set, otherwise the call
+// fails with ErrBreakawayNotAllowed. Note that jobs with WithSilentBreakawayOK
+// limit do not require the flag: all the child processes break away
+// implicitly. If the job is nested, the process breaks away from the parent
+// jobs in the chain only as long as they allow breakaway.
+func StartBreakaway(cmd *exec.Cmd, job *JobObject) error {
+	if err := job.sync(jobapi.QueryInfo, jobapi.JobObjectExtendedLimitInformation); err != nil {
+		return err
+	}
+	if !LimitBreakawayOK.IsSet(job) && !LimitSilentBreakawayOK.IsSet(job) {
+		return ErrBreakawayNotAllowed
+	}
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = new(windows.SysProcAttr)
+	}
+	cmd.SysProcAttr.CreationFlags |= windows.CREATE_BREAKAWAY_FROM_JOB
+	return cmd.Start()
+}
+
+// SuspendedCommand returns the Cmd struct to execute the named program with
+// the given arguments, like exec.Command does. The process of the command
+// is to be created with CREATE_SUSPENDED flag, therefore it does not run
+// until Resume is called: this allows to assign the process to a job object
+// before it starts execution:
+//
+//  cmd := winjob.SuspendedCommand("app.exe")
+//  if err := cmd.Start(); err != nil {
+//    // ...
+//  }
+//  if err := job.Assign(cmd.Process); err != nil {
+//    // ...
+//  }
+//  if err := winjob.Resume(cmd); err != nil {
+//    // ...
+//  }
+//
+// Additional creation flags should be added to the existing ones rather
+// than overwrite them:
+//
+//  cmd.SysProcAttr.CreationFlags |= windows.CREATE_NEW_PROCESS_GROUP
+func SuspendedCommand(name string, arg ...string) *exec.Cmd {
+	cmd := exec.Command(name, arg...)
+	setSuspended(cmd)
+	return cmd
+}
+
+func setSuspended(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = new(windows.SysProcAttr)
+	}
+	cmd.SysProcAttr.CreationFlags |= windows.CREATE_SUSPENDED
+}
+
 // Resume resumes the process of the given command. The command should be
 // created with CREATE_SUSPENDED flag:
 //
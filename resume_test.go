@@ -3,6 +3,7 @@
 package winjob_test
 
 import (
+	"os"
 	"os/exec"
 	"testing"
 
@@ -17,3 +18,83 @@ func TestStart(t *testing.T) {
 		t.Fatalf("Limit is not set after Start")
 	}
 }
+
+func TestRunContained(t *testing.T) {
+	requireNoError(t, winjob.RunContained(exec.Command("cmd.exe", "/c", "exit 0")))
+	err := winjob.RunContained(exec.Command("cmd.exe", "/c", "exit 3"))
+	if e, ok := err.(*exec.ExitError); !ok || e.ExitCode() != 3 {
+		t.Fatalf("Expected exit code 3, got %v", err)
+	}
+}
+
+func TestStartKillOnClose(t *testing.T) {
+	cmd := exec.Command(commandName)
+	job, err := winjob.StartKillOnClose(cmd, winjob.WithActiveProcessLimit(8))
+	requireNoError(t, err)
+	defer job.KeepAlive()
+	requireNoError(t, job.QueryLimits())
+	for _, l := range []winjob.Limit{
+		winjob.LimitKillOnJobClose,
+		winjob.LimitBreakawayOK,
+		winjob.LimitActiveProcess,
+	} {
+		if !l.IsSet(job) {
+			t.Fatalf("Limit %T is not set", l)
+		}
+	}
+	requireNoError(t, job.Close())
+	requireNoError(t, cmd.Wait())
+}
+
+func TestSuspendedCommand(t *testing.T) {
+	runTestWithEmptyJobObject(t, func(job *winjob.JobObject) {
+		cmd := winjob.SuspendedCommand(commandName)
+		requireNoError(t, cmd.Start())
+		requireNoError(t, job.Assign(cmd.Process))
+		requireNoError(t, winjob.Resume(cmd))
+		requireNoError(t, job.Terminate())
+	})
+}
+
+// startBreakawayEnv is set for the test process started by
+// TestStartBreakaway: the process assigns itself to a job object, which
+// would affect other tests otherwise.
+const startBreakawayEnv = "GO_WINJOB_TEST_START_BREAKAWAY"
+
+func TestStartBreakaway(t *testing.T) {
+	if os.Getenv(startBreakawayEnv) == "" {
+		runTestWithEmptyJobObject(t, func(job *winjob.JobObject) {
+			err := winjob.StartBreakaway(exec.Command(commandName), job)
+			if err != winjob.ErrBreakawayNotAllowed {
+				t.Fatalf("Expected %v, got %v", winjob.ErrBreakawayNotAllowed, err)
+			}
+		})
+		cmd := exec.Command(os.Args[0], "-test.run=^TestStartBreakaway$")
+		cmd.Env = append(os.Environ(), startBreakawayEnv+"=1")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("%v: %s", err, out)
+		}
+		return
+	}
+	runTestWithEmptyJobObject(t, func(job *winjob.JobObject) {
+		requireNoError(t, job.SetLimit(winjob.WithBreakawayOK()))
+		requireNoError(t, job.AssignCurrentProcess())
+		for _, x := range []struct {
+			breakaway bool
+			start     func(*exec.Cmd) error
+		}{
+			{false, (*exec.Cmd).Start},
+			{true, func(cmd *exec.Cmd) error { return winjob.StartBreakaway(cmd, job) }},
+		} {
+			cmd := exec.Command(commandName)
+			requireNoError(t, x.start(cmd))
+			contains, err := job.Contains(cmd.Process)
+			requireNoError(t, cmd.Process.Kill())
+			_ = cmd.Wait()
+			requireNoError(t, err)
+			if contains == x.breakaway {
+				t.Fatalf("Breakaway %v: unexpected job association: %v", x.breakaway, contains)
+			}
+		}
+	})
+}
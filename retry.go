@@ -0,0 +1,53 @@
+// +build windows
+
+package winjob
+
+import (
+	"errors"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/windows"
+)
+
+// transientErrors lists system error codes caused by temporary resource
+// exhaustion, after which an operation may succeed if retried.
+var transientErrors = []syscall.Errno{
+	windows.ERROR_NOT_ENOUGH_MEMORY,
+	windows.ERROR_OUTOFMEMORY,
+	windows.ERROR_NO_SYSTEM_RESOURCES,
+	windows.ERROR_WORKING_SET_QUOTA,
+	windows.ERROR_PAGEFILE_QUOTA,
+	windows.ERROR_COMMITMENT_LIMIT,
+}
+
+func isTransient(err error) bool {
+	for _, errno := range transientErrors {
+		if errors.Is(err, errno) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetLimitRetry applies given limits to the job object the same way as
+// SetLimit does, but retries the call up to the given number of attempts if
+// it fails because of a temporary resource exhaustion, e.g. under heavy
+// memory pressure. The backoff doubles after each attempt. Any other error,
+// e.g. ERROR_ACCESS_DENIED, is returned immediately.
+func (job *JobObject) SetLimitRetry(attempts int, backoff time.Duration, limits ...Limit) error {
+	return retry(attempts, backoff, func() error {
+		return job.SetLimit(limits...)
+	})
+}
+
+func retry(attempts int, backoff time.Duration, fn func() error) (err error) {
+	for i := 0; ; i++ {
+		if err = fn(); err == nil || !isTransient(err) || i+1 >= attempts {
+			return err
+		}
+		logf("winjob: attempt %d of %d failed: %v; retrying in %v", i+1, attempts, err, backoff)
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
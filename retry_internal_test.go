@@ -0,0 +1,56 @@
+// +build windows
+
+package winjob
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"golang.org/x/sys/windows"
+)
+
+func TestRetry(t *testing.T) {
+	transient := os.NewSyscallError("SetInformationJobObject", windows.ERROR_NOT_ENOUGH_MEMORY)
+	permanent := os.NewSyscallError("SetInformationJobObject", windows.ERROR_ACCESS_DENIED)
+	for _, x := range []struct {
+		errs     []error
+		attempts int
+		calls    int
+		expected error
+	}{
+		{[]error{nil}, 3, 1, nil},
+		{[]error{transient, nil}, 3, 2, nil},
+		{[]error{transient, transient, transient}, 3, 3, transient},
+		{[]error{permanent}, 3, 1, permanent},
+		{[]error{transient, permanent}, 3, 2, permanent},
+		{[]error{transient}, 0, 1, transient},
+	} {
+		var calls int
+		err := retry(x.attempts, 0, func() error {
+			err := x.errs[calls]
+			calls++
+			return err
+		})
+		if err != x.expected || calls != x.calls {
+			t.Fatalf("Expected %v after %d calls, got %v after %d calls", x.expected, x.calls, err, calls)
+		}
+	}
+}
+
+type testLogger []string
+
+func (l *testLogger) Printf(format string, v ...interface{}) {
+	*l = append(*l, fmt.Sprintf(format, v...))
+}
+
+func TestRetry_Logger(t *testing.T) {
+	var l testLogger
+	SetLogger(&l)
+	defer SetLogger(nil)
+	transient := os.NewSyscallError("SetInformationJobObject", windows.ERROR_NOT_ENOUGH_MEMORY)
+	_ = retry(3, 0, func() error { return transient })
+	if len(l) != 2 {
+		t.Fatalf("Expected 2 log messages, got %q", l)
+	}
+}
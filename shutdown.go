@@ -0,0 +1,212 @@
+// +build windows
+
+package winjob
+
+import (
+	"context"
+	"time"
+)
+
+// ShutdownResult describes how the job object processes were stopped.
+type ShutdownResult struct {
+	// Terminated is true if the job object had to be terminated because
+	// its processes did not exit in time.
+	Terminated bool
+	// Remaining is the number of active processes at the moment the job
+	// object was terminated.
+	Remaining uint32
+}
+
+// shutdownTerminationTimeout is the time Shutdown waits for the processes to
+// exit once the job object has been terminated.
+const shutdownTerminationTimeout = 5 * time.Second
+
+// Shutdown waits for all the job object processes to exit, or the context
+// is done, whichever occurs first, the same way as Wait does. In the latter
+// case the job object is terminated with the exit code given, and the result
+// reports the number of processes that were still active. Since termination
+// is asynchronous, the call then waits up to shutdownTerminationTimeout for
+// the processes to exit.
+func (job *JobObject) Shutdown(ctx context.Context, exitCode uint32) (ShutdownResult, error) {
+	err := job.Wait(ctx)
+	if err == nil || ctx.Err() == nil {
+		return ShutdownResult{}, err
+	}
+	info, err := job.BasicAccounting()
+	if err != nil {
+		return ShutdownResult{}, err
+	}
+	if info.ActiveProcesses == 0 {
+		return ShutdownResult{}, nil
+	}
+	r := ShutdownResult{Terminated: true, Remaining: info.ActiveProcesses}
+	if err = job.TerminateWithExitCode(exitCode); err != nil {
+		return r, err
+	}
+	tctx, cancel := context.WithTimeout(context.Background(), shutdownTerminationTimeout)
+	defer cancel()
+	if err = job.Wait(tctx); err != nil && tctx.Err() == nil {
+		return r, err
+	}
+	return r, nil
+}
+
+// TerminateIfIdle terminates the job object with the exit code given only if
+// the job has no active processes, and reports whether the job has been
+// terminated. The call never disrupts processes that are found running.
+//
+// Note that the check and the termination are not atomic: a process may be
+// associated with the job (or spawned by an exiting process) after the check,
+// in which case the process is terminated along with the job. The caller must
+// guarantee that no processes are assigned to the job concurrently.
+func (job *JobObject) TerminateIfIdle(exitCode uint32) (bool, error) {
+	info, err := job.BasicAccounting()
+	if err != nil {
+		return false, err
+	}
+	if info.ActiveProcesses != 0 {
+		return false, nil
+	}
+	if err = job.TerminateWithExitCode(exitCode); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// WaitActiveProcesses blocks until the job object has at least n active
+// processes, or the context is done, whichever occurs first. In the latter
+// case the context error is returned.
+//
+// The number of active processes is checked on every NotificationNewProcess
+// message. Since message delivery is not guaranteed, the number is also
+// checked periodically. Notifications are received the same way as Wait
+// does: an active subscription is reused, otherwise a completion port is
+// associated with the job object for the duration of the call, therefore
+// Notify can be called once the call returns.
+func (job *JobObject) WaitActiveProcesses(ctx context.Context, n uint32) error {
+	e, release := job.events()
+	defer release()
+	for {
+		info, err := job.BasicAccounting()
+		if err != nil {
+			return err
+		}
+		if info.ActiveProcesses >= n {
+			return nil
+		}
+		if err = waitEvent(ctx, &e, NotificationNewProcess); err != nil {
+			return err
+		}
+	}
+}
+
+// Wait blocks until the job object has no active processes, or the context
+// is done, whichever occurs first. In the latter case the context error is
+// returned. Unlike waiting for a command, the call waits for the whole process
+// tree, including processes spawned by the command.
+//
+// The number of active processes is checked on every
+// NotificationActiveProcessZero message, as well as periodically. If the job
+// object has an active subscription created with Notify, the messages are
+// received from it. Otherwise, a completion port is associated with the job
+// object for the duration of the call. If a completion port can not be
+// associated with the job (e.g. one has been created with CreatePort), the
+// call falls back to periodic checks only.
+func (job *JobObject) Wait(ctx context.Context) error {
+	e, release := job.events()
+	defer release()
+	for {
+		info, err := job.BasicAccounting()
+		if err != nil {
+			return err
+		}
+		if info.ActiveProcesses == 0 {
+			return nil
+		}
+		if err = waitEvent(ctx, &e, NotificationActiveProcessZero); err != nil {
+			return err
+		}
+	}
+}
+
+// jobEvents is a source of the job object notifications for waiters.
+type jobEvents struct {
+	// port is associated with the job object by the waiter.
+	port Port
+	// c receives notifications relayed by an active subscription.
+	c <-chan Notification
+}
+
+// eventsBufferSize is the number of notifications buffered for a waiter that
+// receives them from a subscription. Excess notifications are dropped.
+const eventsBufferSize = 64
+
+// events returns a source of the job object notifications along with the
+// function that releases it. An active subscription is reused, if any,
+// otherwise a completion port is associated with the job object until the
+// release. If neither is possible, the returned source never delivers any
+// notifications.
+func (job *JobObject) events() (jobEvents, func()) {
+	if s := job.subscription; s != nil {
+		if c, unwatch, ok := s.watch(eventsBufferSize); ok {
+			return jobEvents{c: c}, unwatch
+		}
+	}
+	p, err := CreatePort(job)
+	if err != nil {
+		return jobEvents{}, func() {}
+	}
+	return jobEvents{port: p}, func() {
+		// If the port can not be disassociated, the association is kept
+		// tracked, and subsequent calls fail with ErrPortAlreadyAssociated.
+		_ = job.disassociatePort()
+		_ = p.Close()
+	}
+}
+
+// next blocks until the next notification is received, the timeout expires,
+// or the context is done, whichever occurs first. If no notification has been
+// received, ok is false. The context is only checked after the timeout, if
+// the notifications are received from the completion port.
+func (e *jobEvents) next(ctx context.Context, timeout time.Duration) (m Notification, ok bool, err error) {
+	if e.port != 0 {
+		return e.port.NextMessageTimeout(timeout)
+	}
+	t := time.NewTimer(timeout)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return m, false, ctx.Err()
+	case <-t.C:
+		return m, false, nil
+	case m, ok = <-e.c:
+		if !ok {
+			// The subscription is closed: a nil channel blocks forever,
+			// therefore only the timeout is awaited subsequently.
+			e.c = nil
+		}
+		return m, ok, nil
+	}
+}
+
+// waitEvent blocks until a notification of the given type is received,
+// signalPollInterval elapses, or the context is done.
+func waitEvent(ctx context.Context, e *jobEvents, typ NotificationType) error {
+	deadline := time.Now().Add(signalPollInterval)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		d := time.Until(deadline)
+		if d <= 0 {
+			return nil
+		}
+		m, ok, err := e.next(ctx, d)
+		switch {
+		case err != nil:
+			return err
+		case !ok || m.Type == typ:
+			return nil
+		}
+	}
+}
@@ -0,0 +1,227 @@
+// +build windows
+
+package winjob_test
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/kolesnikovae/go-winjob"
+)
+
+func TestShutdown_Terminated(t *testing.T) {
+	runTestWithTestJobObjectWithProcess(t, func(job *winjob.JobObject, _ *os.Process) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+		defer cancel()
+		r, err := job.Shutdown(ctx, 1)
+		requireNoError(t, err)
+		if !r.Terminated || r.Remaining == 0 {
+			t.Fatalf("Unexpected shutdown result: %+v", r)
+		}
+		info, err := job.BasicAccounting()
+		requireNoError(t, err)
+		if info.ActiveProcesses != 0 {
+			t.Fatalf("Expected no active processes, got %d", info.ActiveProcesses)
+		}
+	})
+}
+
+func TestShutdown_Completed(t *testing.T) {
+	runTestWithEmptyJobObject(t, func(job *winjob.JobObject) {
+		r, err := job.Shutdown(context.Background(), 1)
+		requireNoError(t, err)
+		if r.Terminated || r.Remaining != 0 {
+			t.Fatalf("Unexpected shutdown result: %+v", r)
+		}
+	})
+}
+
+func TestWaitActiveProcesses(t *testing.T) {
+	runTestWithEmptyJobObject(t, func(job *winjob.JobObject) {
+		ctx, cancel := context.WithTimeout(context.Background(), notificationsTestLimit)
+		defer cancel()
+		errs := make(chan error, 1)
+		go func() {
+			errs <- job.WaitActiveProcesses(ctx, 2)
+		}()
+		for i := 0; i < 2; i++ {
+			requireNoError(t, winjob.StartInJobObject(exec.Command(commandName), job))
+		}
+		defer func() {
+			requireNoError(t, job.Terminate())
+		}()
+		requireNoError(t, <-errs)
+	})
+}
+
+func TestWaitActiveProcesses_Notify(t *testing.T) {
+	runTestWithEmptyJobObject(t, func(job *winjob.JobObject) {
+		ctx, cancel := context.WithTimeout(context.Background(), notificationsTestLimit)
+		defer cancel()
+		requireNoError(t, winjob.StartInJobObject(exec.Command(commandName), job))
+		defer func() {
+			requireNoError(t, job.Terminate())
+		}()
+		requireNoError(t, job.WaitActiveProcesses(ctx, 1))
+		c := make(chan winjob.Notification, 1)
+		s, err := winjob.Notify(c, job)
+		requireNoError(t, err)
+		requireNoError(t, s.Close())
+	})
+}
+
+func TestWaitActiveProcesses_Timeout(t *testing.T) {
+	runTestWithEmptyJobObject(t, func(job *winjob.JobObject) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*200)
+		defer cancel()
+		if err := job.WaitActiveProcesses(ctx, 1); err != context.DeadlineExceeded {
+			t.Fatalf("Expected %v, got %v", context.DeadlineExceeded, err)
+		}
+	})
+}
+
+func TestTerminateIfIdle(t *testing.T) {
+	runTestWithTestJobObjectWithProcess(t, func(job *winjob.JobObject, _ *os.Process) {
+		terminated, err := job.TerminateIfIdle(1)
+		requireNoError(t, err)
+		if terminated {
+			t.Fatal("Job object with active processes is not expected to be terminated")
+		}
+	})
+	runTestWithEmptyJobObject(t, func(job *winjob.JobObject) {
+		terminated, err := job.TerminateIfIdle(1)
+		requireNoError(t, err)
+		if !terminated {
+			t.Fatal("Idle job object is expected to be terminated")
+		}
+	})
+}
+
+func TestCollectExitCodes(t *testing.T) {
+	runTestWithEmptyJobObject(t, func(job *winjob.JobObject) {
+		cmd := exec.Command(commandName)
+		requireNoError(t, winjob.StartInJobObject(cmd, job))
+		defer func() {
+			_ = cmd.Wait()
+		}()
+		ctx, cancel := context.WithTimeout(context.Background(), notificationsTestLimit)
+		defer cancel()
+		go func() {
+			time.Sleep(time.Millisecond * 200)
+			_ = job.TerminateWithExitCode(7)
+		}()
+		codes, err := job.CollectExitCodes(ctx)
+		requireNoError(t, err)
+		if code, ok := codes[cmd.Process.Pid]; !ok || code != 7 {
+			t.Fatalf("Unexpected exit codes: %v", codes)
+		}
+	})
+}
+
+func TestCollectExitCodes_Timeout(t *testing.T) {
+	runTestWithEmptyJobObject(t, func(job *winjob.JobObject) {
+		c := make(chan winjob.Notification, 8)
+		s, err := winjob.Notify(c, job)
+		requireNoError(t, err)
+		defer func() {
+			requireNoError(t, s.Close())
+		}()
+		exited := exec.Command(commandName)
+		requireNoError(t, winjob.StartInJobObject(exited, job))
+		requireNoError(t, winjob.StartInJobObject(exec.Command(commandName), job))
+		defer func() {
+			requireNoError(t, job.Terminate())
+		}()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*500)
+		defer cancel()
+		go func() {
+			time.Sleep(time.Millisecond * 200)
+			_ = exited.Process.Kill()
+		}()
+		codes, err := job.CollectExitCodes(ctx)
+		if err != context.DeadlineExceeded {
+			t.Fatalf("Expected %v, got %v", context.DeadlineExceeded, err)
+		}
+		_ = exited.Wait()
+		if _, ok := codes[exited.Process.Pid]; !ok || len(codes) != 1 {
+			t.Fatalf("Unexpected exit codes: %v", codes)
+		}
+	})
+}
+
+func TestWait(t *testing.T) {
+	runTestWithEmptyJobObject(t, func(job *winjob.JobObject) {
+		cmd := exec.Command("cmd.exe", "/c", "exit")
+		requireNoError(t, winjob.StartInJobObject(cmd, job))
+		defer func() {
+			_ = cmd.Wait()
+		}()
+		ctx, cancel := context.WithTimeout(context.Background(), notificationsTestLimit)
+		defer cancel()
+		requireNoError(t, job.Wait(ctx))
+	})
+}
+
+func TestWait_Notify(t *testing.T) {
+	runTestWithEmptyJobObject(t, func(job *winjob.JobObject) {
+		ctx, cancel := context.WithTimeout(context.Background(), notificationsTestLimit)
+		defer cancel()
+		requireNoError(t, job.Wait(ctx))
+		// The completion port association is released on return.
+		c := make(chan winjob.Notification, 1)
+		s, err := winjob.Notify(c, job)
+		requireNoError(t, err)
+		defer func() {
+			requireNoError(t, s.Close())
+		}()
+		requireNoError(t, winjob.StartInJobObject(exec.Command(commandName), job))
+		defer func() {
+			requireNoError(t, job.Terminate())
+		}()
+		select {
+		case n := <-c:
+			t.Logf("Notification: %#v", n)
+		case <-time.After(notificationsTestLimit):
+			t.Fatal("No notifications received")
+		}
+	})
+}
+
+func TestWait_Subscription(t *testing.T) {
+	runTestWithEmptyJobObject(t, func(job *winjob.JobObject) {
+		c := make(chan winjob.Notification, 8)
+		s, err := winjob.Notify(c, job)
+		requireNoError(t, err)
+		defer func() {
+			requireNoError(t, s.Close())
+		}()
+		cmd := exec.Command("cmd.exe", "/c", "exit")
+		requireNoError(t, winjob.StartInJobObject(cmd, job))
+		defer func() {
+			_ = cmd.Wait()
+		}()
+		ctx, cancel := context.WithTimeout(context.Background(), notificationsTestLimit)
+		defer cancel()
+		requireNoError(t, job.Wait(ctx))
+		// The subscription keeps delivering notifications.
+		select {
+		case n := <-c:
+			t.Logf("Notification: %#v", n)
+		case <-time.After(notificationsTestLimit):
+			t.Fatal("No notifications received")
+		}
+	})
+}
+
+func TestWait_Timeout(t *testing.T) {
+	runTestWithTestJobObjectWithProcess(t, func(job *winjob.JobObject, _ *os.Process) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*200)
+		defer cancel()
+		if err := job.Wait(ctx); err != context.DeadlineExceeded {
+			t.Fatalf("Expected %v, got %v", context.DeadlineExceeded, err)
+		}
+	})
+}
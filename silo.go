@@ -0,0 +1,78 @@
+// +build windows
+
+package winjob
+
+import (
+	"errors"
+	"fmt"
+
+	"golang.org/x/sys/windows"
+
+	"github.com/kolesnikovae/go-winjob/jobapi"
+)
+
+// IsSilo reports whether the job object has been converted to a silo.
+// Silos are supported starting with Windows 10, version 1607 and Windows
+// Server 2016; ErrNotSupported is returned on older systems.
+func (job *JobObject) IsSilo() (bool, error) {
+	if err := job.valid(); err != nil {
+		return false, err
+	}
+	if err := requireBuild(buildWindows10v1607, "silo"); err != nil {
+		return false, err
+	}
+	var info jobapi.SILOOBJECT_BASIC_INFORMATION
+	err := jobapi.QueryInfo(job.Handle, jobapi.JobObjectSiloBasicInformation, &info)
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.Is(err, windows.ERROR_INVALID_PARAMETER):
+		// The information class is only valid for silos.
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+// SiloRootDirectory queries the root directory of the silo. The call fails
+// if the job object has not been converted to a silo. Silos are supported
+// starting with Windows 10, version 1607 and Windows Server 2016;
+// ErrNotSupported is returned on older systems.
+func (job *JobObject) SiloRootDirectory() (string, error) {
+	if err := job.valid(); err != nil {
+		return "", err
+	}
+	if err := requireBuild(buildWindows10v1607, "silo"); err != nil {
+		return "", err
+	}
+	return jobapi.QuerySiloRootDirectory(job.Handle)
+}
+
+// ErrNotServerSilo is returned if the job object is expected to be a server
+// silo, but it is not.
+var ErrNotServerSilo = errors.New("job object is not a server silo")
+
+// ServerSiloUserSharedData queries the server silo view of the system data
+// shared with user mode (KUSER_SHARED_DATA), e.g. the system root as seen
+// by the silo processes. If the job object is not a server silo,
+// ErrNotServerSilo is returned. Server silos are supported starting with
+// Windows Server 2016; ErrNotSupported is returned on older systems.
+//
+// Note that JobObjectServerSiloUserSharedData information class is not
+// documented by Microsoft.
+func (job *JobObject) ServerSiloUserSharedData() (*jobapi.SILO_USER_SHARED_DATA, error) {
+	if err := job.valid(); err != nil {
+		return nil, err
+	}
+	if err := requireBuild(buildWindows10v1607, "server silo"); err != nil {
+		return nil, err
+	}
+	data, err := jobapi.QueryServerSiloUserSharedData(job.Handle)
+	if err == nil {
+		return data, nil
+	}
+	if silo, siloErr := job.IsSilo(); siloErr == nil && !silo {
+		return nil, fmt.Errorf("%w: %v", ErrNotServerSilo, err)
+	}
+	return nil, err
+}
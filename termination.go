@@ -0,0 +1,59 @@
+// +build windows
+
+package winjob
+
+import (
+	"golang.org/x/sys/windows"
+
+	"github.com/kolesnikovae/go-winjob/jobapi"
+)
+
+// TerminationPolicy describes what happens to the job object processes when
+// the job object handles are closed.
+type TerminationPolicy string
+
+const (
+	// TerminationPolicyNone means that the processes keep running after all
+	// the job object handles are closed, including the case when the process
+	// that created the job object exits.
+	TerminationPolicyNone TerminationPolicy = "None"
+	// TerminationPolicyKillOnLastHandleClose means that the processes are
+	// terminated when the last job object handle is closed. The handle is not
+	// inheritable, therefore, unless it has been duplicated, the processes
+	// are terminated once the handle is closed, or the process that owns the
+	// handle exits.
+	TerminationPolicyKillOnLastHandleClose TerminationPolicy = "KillOnLastHandleClose"
+	// TerminationPolicyKillOnLastHandleCloseInherited is similar to
+	// TerminationPolicyKillOnLastHandleClose, but the handle is inheritable:
+	// child processes may hold the inherited handles and keep the job object
+	// processes running after the process that created the job exits.
+	TerminationPolicyKillOnLastHandleCloseInherited TerminationPolicy = "KillOnLastHandleCloseInherited"
+)
+
+// TerminationPolicy queries the job object for WithKillOnJobClose limit and
+// examines the handle inheritance to describe what happens to the processes
+// when the job object handles are closed.
+//
+// Note that the job object processes are never terminated just because the
+// process that created the job object exits: WithKillOnJobClose causes the
+// termination when the last handle to the job object is closed. Since the
+// system closes the handles of a process that exits, the two only coincide
+// if no other process holds a handle to the job object (e.g. it has not been
+// inherited, duplicated, or opened by name).
+func (job *JobObject) TerminationPolicy() (TerminationPolicy, error) {
+	err := job.sync(jobapi.QueryInfo, jobapi.JobObjectExtendedLimitInformation)
+	if err != nil {
+		return "", err
+	}
+	if !LimitKillOnJobClose.IsSet(job) {
+		return TerminationPolicyNone, nil
+	}
+	flags, err := jobapi.GetHandleInformation(job.Handle)
+	if err != nil {
+		return "", err
+	}
+	if flags&windows.HANDLE_FLAG_INHERIT != 0 {
+		return TerminationPolicyKillOnLastHandleCloseInherited, nil
+	}
+	return TerminationPolicyKillOnLastHandleClose, nil
+}